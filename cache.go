@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SourceMeta captures the freshness signal observed the last time source was
+// fetched, so a later fetch can ask the origin whether anything changed
+// instead of re-downloading and re-parsing unconditionally.
+type SourceMeta struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ModTime      time.Time `json:"mod_time,omitempty"`
+}
+
+// ContentSource fetches a VHD build log. When prev is non-nil, implementations
+// should ask the origin whether the content has changed since prev was
+// recorded and report notModified=true rather than returning a reader if not.
+type ContentSource interface {
+	Fetch(source string, prev *SourceMeta) (reader io.ReadCloser, meta *SourceMeta, notModified bool, err error)
+}
+
+// httpContentSource fetches a VHD build log over HTTP(S), using conditional
+// GET headers to avoid re-downloading unchanged content.
+type httpContentSource struct{}
+
+func (httpContentSource) Fetch(source string, prev *SourceMeta) (io.ReadCloser, *SourceMeta, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, source, nil)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to build request for %s: %w", source, err)
+	}
+	if prev != nil {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	fmt.Printf("Fetching content from URL: %s\n", source)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to fetch URL %s: %v", source, err)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return nil, prev, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, nil, false, fmt.Errorf("HTTP error %d when fetching %s", resp.StatusCode, source)
+	}
+
+	meta := &SourceMeta{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	return resp.Body, meta, false, nil
+}
+
+// fileContentSource reads a VHD build log from local disk, comparing mtimes
+// to avoid re-parsing a file that hasn't changed.
+type fileContentSource struct{}
+
+func (fileContentSource) Fetch(source string, prev *SourceMeta) (io.ReadCloser, *SourceMeta, bool, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to stat file %s: %w", source, err)
+	}
+
+	modTime := info.ModTime()
+	if prev != nil && prev.ModTime.Equal(modTime) {
+		return nil, prev, true, nil
+	}
+
+	fmt.Printf("Reading local file: %s\n", source)
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to open file %s: %w", source, err)
+	}
+	return f, &SourceMeta{ModTime: modTime}, false, nil
+}
+
+// sourceForContent returns the ContentSource that can fetch source, mirroring
+// the URL/file split fetchContent already makes.
+func sourceForContent(source string) ContentSource {
+	if isURL(source) {
+		return httpContentSource{}
+	}
+	return fileContentSource{}
+}
+
+// cacheEntry is what ResultCache persists to disk for a single source: the
+// freshness signal it was fetched with, and the parsed result.
+type cacheEntry struct {
+	Meta   SourceMeta   `json:"meta"`
+	Result *ParseResult `json:"result"`
+}
+
+// ResultCache caches parsed VHD output on disk, keyed by source URL/path, so
+// repeated scans of the same source across a CI matrix skip re-downloading
+// and re-parsing when the content hasn't changed.
+type ResultCache struct {
+	dir string
+}
+
+// NewResultCache creates a ResultCache rooted at dir, creating it if
+// necessary. An empty dir defaults to $XDG_CACHE_HOME/oval-package-parser
+// (os.UserCacheDir's behavior).
+func NewResultCache(dir string) (*ResultCache, error) {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine cache directory: %w", err)
+		}
+		dir = filepath.Join(base, "oval-package-parser")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+	return &ResultCache{dir: dir}, nil
+}
+
+func (c *ResultCache) path(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *ResultCache) load(source string) *cacheEntry {
+	data, err := os.ReadFile(c.path(source))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+func (c *ResultCache) store(source string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	return os.WriteFile(c.path(source), data, 0o644)
+}
+
+// FetchAndParse fetches source, reusing the cached ParseResult when its
+// ETag/Last-Modified (URLs) or mtime (local files) shows the content hasn't
+// changed, and parses it with p otherwise.
+func (c *ResultCache) FetchAndParse(p *Parser, source string) (*ParseResult, error) {
+	src := sourceForContent(source)
+
+	cached := c.load(source)
+	var prevMeta *SourceMeta
+	if cached != nil {
+		prevMeta = &cached.Meta
+	}
+
+	reader, meta, notModified, err := src.Fetch(source, prevMeta)
+	if err != nil {
+		return nil, err
+	}
+	if notModified && cached != nil {
+		return cached.Result, nil
+	}
+	defer reader.Close()
+
+	result, err := p.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta != nil {
+		if err := c.store(source, cacheEntry{Meta: *meta, Result: result}); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to cache result for %s: %v\n", source, err)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultCache_FetchAndParse_LocalFile(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "test-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	content := "=== Installed Packages Begin\nadduser/noble,now 3.137ubuntu1 all [installed,automatic]\n=== Installed Packages End"
+	if _, err := tempFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	tempFile.Close()
+
+	cacheDir := t.TempDir()
+	cache, err := NewResultCache(cacheDir)
+	if err != nil {
+		t.Fatalf("NewResultCache() failed: %v", err)
+	}
+	p, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	result, err := cache.FetchAndParse(p, tempFile.Name())
+	if err != nil {
+		t.Fatalf("FetchAndParse() failed: %v", err)
+	}
+	if len(result.Packages) != 1 {
+		t.Fatalf("len(result.Packages) = %d, want 1", len(result.Packages))
+	}
+
+	info, err := os.Stat(tempFile.Name())
+	if err != nil {
+		t.Fatalf("Stat() failed: %v", err)
+	}
+	origModTime := info.ModTime()
+
+	updatedContent := "=== Installed Packages Begin\nbash/noble,now 5.2.21-2ubuntu4 amd64 [installed]\n=== Installed Packages End"
+
+	// Change the content but keep the mtime pinned: the cache should still
+	// reuse the previously parsed result since its freshness check is mtime-based.
+	if err := os.WriteFile(tempFile.Name(), []byte(updatedContent), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite temp file: %v", err)
+	}
+	if err := os.Chtimes(tempFile.Name(), origModTime, origModTime); err != nil {
+		t.Fatalf("Failed to pin mtime: %v", err)
+	}
+
+	cachedResult, err := cache.FetchAndParse(p, tempFile.Name())
+	if err != nil {
+		t.Fatalf("FetchAndParse() (cached) failed: %v", err)
+	}
+	if len(cachedResult.Packages) != 1 || cachedResult.Packages[0].Name != "adduser" {
+		t.Errorf("cachedResult.Packages = %v, want the stale cached [adduser] entry", cachedResult.Packages)
+	}
+
+	// Now bump the mtime forward: the cache should detect the change and reparse.
+	newModTime := origModTime.Add(time.Second)
+	if err := os.Chtimes(tempFile.Name(), newModTime, newModTime); err != nil {
+		t.Fatalf("Failed to bump mtime: %v", err)
+	}
+
+	freshResult, err := cache.FetchAndParse(p, tempFile.Name())
+	if err != nil {
+		t.Fatalf("FetchAndParse() (fresh) failed: %v", err)
+	}
+	if len(freshResult.Packages) != 1 || freshResult.Packages[0].Name != "bash" {
+		t.Errorf("freshResult.Packages = %v, want the reparsed [bash] entry", freshResult.Packages)
+	}
+}
+
+func TestResultCache_FetchAndParse_URL_ConditionalGet(t *testing.T) {
+	content := "=== Installed Packages Begin\nadduser/noble,now 3.137ubuntu1 all [installed,automatic]\n=== Installed Packages End"
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"etag-1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"etag-1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	cache, err := NewResultCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewResultCache() failed: %v", err)
+	}
+	p, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	first, err := cache.FetchAndParse(p, server.URL)
+	if err != nil {
+		t.Fatalf("first FetchAndParse() failed: %v", err)
+	}
+	second, err := cache.FetchAndParse(p, server.URL)
+	if err != nil {
+		t.Fatalf("second FetchAndParse() failed: %v", err)
+	}
+
+	if requests != 2 {
+		t.Fatalf("server saw %d requests, want 2 (full fetch + conditional 304)", requests)
+	}
+	if len(first.Packages) != len(second.Packages) {
+		t.Errorf("cached result package count = %d, want %d", len(second.Packages), len(first.Packages))
+	}
+	if !strings.Contains(first.Packages[0].Name, "adduser") {
+		t.Errorf("unexpected package name %q", first.Packages[0].Name)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// CopaUpdate describes a single package Copacetic's `patch --report` should
+// install, mirroring the subset of fields `copa patch` reads from its report
+// input: the package's current identity, the version to upgrade to, and
+// enough distro context to pick the right package manager.
+type CopaUpdate struct {
+	Name             string `json:"name"`
+	InstalledVersion string `json:"installed_version"`
+	FixedVersion     string `json:"fixed_version"`
+	VulnerabilityID  string `json:"vulnerability_id"`
+	Type             string `json:"type"`              // package-manager family: dpkg, rpm, apk, pacman
+	Distro           string `json:"distro"`            // OSRelease.ID
+	DistroVersionID  string `json:"distro_version_id"` // OSRelease.VersionID
+}
+
+// CopaManifest is the top-level document `copa patch --report` consumes.
+type CopaManifest struct {
+	Updates []CopaUpdate `json:"updates"`
+}
+
+// buildCopaManifest converts scan findings into a CopaManifest, emitting one
+// CopaUpdate per (package, vulnerability) pair that has a FixedVersion —
+// vulnerabilities with no fix give Copacetic nothing to install, so they're
+// omitted rather than forwarded as unreachable line items.
+func buildCopaManifest(findings []vulnFinding, osRelease *OSRelease) *CopaManifest {
+	manifest := &CopaManifest{Updates: make([]CopaUpdate, 0, len(findings))}
+	for _, finding := range findings {
+		for _, vuln := range finding.vulns {
+			if vuln.FixedVersion == "" {
+				continue
+			}
+			manifest.Updates = append(manifest.Updates, CopaUpdate{
+				Name:             finding.pkg.Name,
+				InstalledVersion: finding.pkg.Version,
+				FixedVersion:     vuln.FixedVersion,
+				VulnerabilityID:  vuln.VulnerabilityID,
+				Type:             osRelease.PackageFormat.String(),
+				Distro:           osRelease.ID,
+				DistroVersionID:  osRelease.VersionID,
+			})
+		}
+	}
+	return manifest
+}
+
+// writeCopaManifest builds a CopaManifest from findings and osRelease and
+// writes it to w as JSON.
+func writeCopaManifest(w io.Writer, findings []vulnFinding, osRelease *OSRelease) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(buildCopaManifest(findings, osRelease))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+func TestBuildCopaManifest(t *testing.T) {
+	osRelease := &OSRelease{
+		ID:            "ubuntu",
+		VersionID:     "24.04",
+		Distro:        DistroUbuntu,
+		PackageFormat: PackageFormatDpkg,
+	}
+
+	findings := []vulnFinding{
+		{
+			pkg: OSPackage{Name: "adduser", Version: "3.137ubuntu1"},
+			vulns: []types.Advisory{
+				createMockAdvisory("CVE-2023-1234", "3.137ubuntu2"), // fixable
+				createMockAdvisory("CVE-2023-5678", ""),             // no fix, dropped
+			},
+		},
+		{
+			pkg:   OSPackage{Name: "coreutils", Version: "9.4-2"},
+			vulns: nil,
+		},
+	}
+
+	manifest := buildCopaManifest(findings, osRelease)
+
+	if len(manifest.Updates) != 1 {
+		t.Fatalf("len(Updates) = %d, want 1", len(manifest.Updates))
+	}
+
+	update := manifest.Updates[0]
+	if update.Name != "adduser" {
+		t.Errorf("Name = %q, want %q", update.Name, "adduser")
+	}
+	if update.InstalledVersion != "3.137ubuntu1" {
+		t.Errorf("InstalledVersion = %q, want %q", update.InstalledVersion, "3.137ubuntu1")
+	}
+	if update.FixedVersion != "3.137ubuntu2" {
+		t.Errorf("FixedVersion = %q, want %q", update.FixedVersion, "3.137ubuntu2")
+	}
+	if update.VulnerabilityID != "CVE-2023-1234" {
+		t.Errorf("VulnerabilityID = %q, want %q", update.VulnerabilityID, "CVE-2023-1234")
+	}
+	if update.Type != "dpkg" {
+		t.Errorf("Type = %q, want %q", update.Type, "dpkg")
+	}
+	if update.Distro != "ubuntu" {
+		t.Errorf("Distro = %q, want %q", update.Distro, "ubuntu")
+	}
+	if update.DistroVersionID != "24.04" {
+		t.Errorf("DistroVersionID = %q, want %q", update.DistroVersionID, "24.04")
+	}
+}
+
+// TestWriteCopaManifest_RoundTrip verifies a written manifest decodes back
+// into the same updates Copacetic's patch command would read.
+func TestWriteCopaManifest_RoundTrip(t *testing.T) {
+	osRelease := &OSRelease{
+		ID:            "azurelinux",
+		VersionID:     "3.0",
+		Distro:        DistroAzureLinux,
+		PackageFormat: PackageFormatRPM,
+	}
+
+	findings := []vulnFinding{
+		{
+			pkg: OSPackage{Name: "glibc", Version: "2.38-4.azl3"},
+			vulns: []types.Advisory{
+				createMockAdvisory("CVE-2023-9999", "2.38-5.azl3"),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeCopaManifest(&buf, findings, osRelease); err != nil {
+		t.Fatalf("writeCopaManifest() error = %v", err)
+	}
+
+	var decoded CopaManifest
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to decode manifest: %v", err)
+	}
+
+	want := buildCopaManifest(findings, osRelease)
+	if len(decoded.Updates) != len(want.Updates) {
+		t.Fatalf("len(decoded.Updates) = %d, want %d", len(decoded.Updates), len(want.Updates))
+	}
+	if decoded.Updates[0] != want.Updates[0] {
+		t.Errorf("decoded.Updates[0] = %+v, want %+v", decoded.Updates[0], want.Updates[0])
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apkversion "github.com/knqyf263/go-apk-version"
+	debversion "github.com/knqyf263/go-deb-version"
+	rpmversion "github.com/knqyf263/go-rpm-version"
+)
+
+// DistroHandler adapts a single package format - the line grammar used to
+// list installed packages and the version-comparison semantics that format
+// requires - to the Parser. NewParser registers a handler each for the
+// dpkg, RPM, and apk families; third parties wanting another format (e.g.
+// Gentoo's Portage or Arch's pacman) implement this interface and register
+// it with NewParserWithHandlers instead of modifying the parser itself.
+type DistroHandler interface {
+	// ID returns the representative OSDistro this handler is registered
+	// under, used to label a distro resolved by package-count fallback
+	// when no os-release block identified one explicitly.
+	ID() OSDistro
+	// Matches reports whether osRelease was produced by a distro this
+	// handler parses packages for. osRelease is nil when the build log had
+	// no os-release (or legacy release) block.
+	Matches(osRelease *OSRelease) bool
+	// ParsePackageLine parses a single "Installed Packages" line in this
+	// handler's format, returning nil if line doesn't match.
+	ParsePackageLine(line string) *OSPackage
+	// CompareVersions compares two version strings using this format's
+	// semantics, returning a negative number, zero, or a positive number as
+	// a is less than, equal to, or greater than b. It returns 0 if either
+	// version fails to parse.
+	CompareVersions(a, b string) int
+}
+
+// dpkgHandler parses `apt list --installed`-style lines for Debian/Ubuntu's
+// dpkg/apt package format.
+type dpkgHandler struct {
+	lineRegex *regexp.Regexp
+}
+
+// newDpkgHandler compiles the dpkg DistroHandler's regex patterns.
+func newDpkgHandler() (*dpkgHandler, error) {
+	// package/source,now version arch [status]
+	lineRegex, err := regexp.Compile(`^([^/]+)/.*,now\s+(\S+)\s+\S+\s+\[.*\]`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile dpkg line regex: %w", err)
+	}
+	return &dpkgHandler{lineRegex: lineRegex}, nil
+}
+
+func (h *dpkgHandler) ID() OSDistro { return DistroUbuntu }
+
+func (h *dpkgHandler) Matches(osRelease *OSRelease) bool {
+	return osRelease != nil && osRelease.Distro.PackageFormat() == PackageFormatDpkg
+}
+
+func (h *dpkgHandler) ParsePackageLine(line string) *OSPackage {
+	if line == "" {
+		return nil
+	}
+	matches := h.lineRegex.FindStringSubmatch(line)
+	if len(matches) < 3 {
+		return nil
+	}
+	return &OSPackage{Name: matches[1], Version: matches[2]}
+}
+
+func (h *dpkgHandler) CompareVersions(a, b string) int {
+	av, err := debversion.NewVersion(a)
+	if err != nil {
+		return 0
+	}
+	bv, err := debversion.NewVersion(b)
+	if err != nil {
+		return 0
+	}
+	return av.Compare(bv)
+}
+
+// rpmHandler parses `rpm -qa`-style "name-version-release.dist.arch" lines
+// for RPM-based distros (Azure Linux, CBL-Mariner, RHEL-likes, SUSE).
+type rpmHandler struct {
+	lineRegex *regexp.Regexp
+}
+
+// newRPMHandler compiles the rpm DistroHandler's regex patterns.
+func newRPMHandler() (*rpmHandler, error) {
+	// package-version-release.dist.arch (supports azl3, cm2, and SUSE dist
+	// tags such as .suse.lp154 and the bare numeric build tags SUSE CVRF
+	// advisories use, e.g. .150500, plus the RHEL-family .elN/.fcN/.olN tags
+	// used by RHEL/CentOS/Rocky/Alma, Fedora, and Oracle Linux respectively)
+	// across x86_64, aarch64, and noarch.
+	lineRegex, err := regexp.Compile(`^(.+)-([^-]+\.(?:azl3|cm2|suse\.lp\d+|el\d+|fc\d+|ol\d+|\d+)\.(?:x86_64|aarch64|noarch))$`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile rpm line regex: %w", err)
+	}
+	return &rpmHandler{lineRegex: lineRegex}, nil
+}
+
+func (h *rpmHandler) ID() OSDistro { return DistroAzureLinux }
+
+func (h *rpmHandler) Matches(osRelease *OSRelease) bool {
+	return osRelease != nil && osRelease.Distro.PackageFormat() == PackageFormatRPM
+}
+
+func (h *rpmHandler) ParsePackageLine(line string) *OSPackage {
+	if line == "" {
+		return nil
+	}
+
+	matches := h.lineRegex.FindStringSubmatch(line)
+	if len(matches) < 3 {
+		return nil
+	}
+	namePart := matches[1]
+	versionPart := matches[2]
+
+	// Extract the release version including .azl3/.cm2 but excluding architecture
+	// versionPart format: "3.azl3.x86_64" or "20.cm2.x86_64" -> we want "3.azl3" or "20.cm2"
+	releaseVersion := versionPart
+	if idx := strings.LastIndex(versionPart, "."); idx != -1 {
+		// Check if the last part is architecture (x86_64, aarch64, or noarch)
+		arch := versionPart[idx+1:]
+		if arch == "x86_64" || arch == "aarch64" || arch == "noarch" {
+			releaseVersion = versionPart[:idx]
+		}
+	}
+
+	// Split name and version for RPM packages
+	if nameVersionMatches := nameVersionRegex.FindStringSubmatch(namePart); len(nameVersionMatches) >= 3 {
+		return &OSPackage{
+			Name:    nameVersionMatches[1],
+			Version: nameVersionMatches[2] + "-" + releaseVersion,
+		}
+	}
+
+	// Fallback for RPM packages where name-version split fails
+	return &OSPackage{
+		Name:    namePart,
+		Version: releaseVersion,
+	}
+}
+
+func (h *rpmHandler) CompareVersions(a, b string) int {
+	return rpmversion.NewVersion(a).Compare(rpmversion.NewVersion(b))
+}
+
+// apkHandler parses `apk info -v`-style "name-version-rN" lines for Alpine's
+// apk package format.
+type apkHandler struct {
+	lineRegex *regexp.Regexp
+}
+
+// newAPKHandler compiles the apk DistroHandler's regex patterns.
+func newAPKHandler() (*apkHandler, error) {
+	// package-version-rN, e.g. "musl-1.2.4-r2". The trailing "-rN" build tag
+	// is what tells an apk line apart from a bare rpm NEVRA, which has no
+	// such tag and instead ends in a ".dist.arch" suffix.
+	lineRegex, err := regexp.Compile(`^(.+)-([0-9][^-]*-r\d+)$`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile apk line regex: %w", err)
+	}
+	return &apkHandler{lineRegex: lineRegex}, nil
+}
+
+func (h *apkHandler) ID() OSDistro { return DistroAlpine }
+
+func (h *apkHandler) Matches(osRelease *OSRelease) bool {
+	return osRelease != nil && osRelease.Distro.PackageFormat() == PackageFormatAPK
+}
+
+func (h *apkHandler) ParsePackageLine(line string) *OSPackage {
+	if line == "" {
+		return nil
+	}
+	matches := h.lineRegex.FindStringSubmatch(line)
+	if len(matches) < 3 {
+		return nil
+	}
+	return &OSPackage{Name: matches[1], Version: matches[2]}
+}
+
+func (h *apkHandler) CompareVersions(a, b string) int {
+	av, err := apkversion.NewVersion(a)
+	if err != nil {
+		return 0
+	}
+	bv, err := apkversion.NewVersion(b)
+	if err != nil {
+		return 0
+	}
+	return av.Compare(bv)
+}
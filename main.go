@@ -1,20 +1,50 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
 	"strings"
+	"syscall"
 
 	"github.com/aquasecurity/trivy-db/pkg/db"
 	"github.com/aquasecurity/trivy-db/pkg/types"
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/azure"
+	susecvrf "github.com/aquasecurity/trivy-db/pkg/vulnsrc/suse-cvrf"
 	"github.com/aquasecurity/trivy-db/pkg/vulnsrc/ubuntu"
+	vulnsrcvuln "github.com/aquasecurity/trivy-db/pkg/vulnsrc/vulnerability"
 	debversion "github.com/knqyf263/go-deb-version"
 	rpmversion "github.com/knqyf263/go-rpm-version"
+
+	"github.com/bahe-msft/oval-package-parser/sbom"
+)
+
+// outputFormat identifies how main renders a ParseResult.
+type outputFormat string
+
+const (
+	formatText          outputFormat = "text"
+	formatSBOMCycloneDX outputFormat = "sbom-cyclonedx"
+	formatSBOMSPDX      outputFormat = "sbom-spdx"
+	formatCopa          outputFormat = "copa"
 )
 
+// parseOutputFormat validates the --format flag value.
+func parseOutputFormat(value string) (outputFormat, error) {
+	switch outputFormat(value) {
+	case formatText, formatSBOMCycloneDX, formatSBOMSPDX, formatCopa:
+		return outputFormat(value), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (want text, sbom-cyclonedx, sbom-spdx, or copa)", value)
+	}
+}
+
 func fatalIfErr(err error) {
 	if err != nil {
 		panic(err)
@@ -67,7 +97,7 @@ func isPackageFixed(pkgVersion, fixedVersion string, distro OSDistro) bool {
 			return false
 		}
 		return pkgVer.Compare(fixedVer) >= 0
-	case DistroAzureLinux, DistroMariner:
+	case DistroAzureLinux, DistroMariner, DistroSUSE:
 		pkgVer := rpmversion.NewVersion(pkgVersion)
 		fixedVer := rpmversion.NewVersion(fixedVersion)
 		return pkgVer.Compare(fixedVer) >= 0
@@ -76,79 +106,521 @@ func isPackageFixed(pkgVersion, fixedVersion string, distro OSDistro) bool {
 	}
 }
 
-// checkPackageVulnerabilities checks for vulnerabilities in a given package
-// based on the OS distribution and version. Returns only unfixed vulnerabilities.
-func checkPackageVulnerabilities(pkg OSPackage, osRelease *OSRelease) ([]types.Advisory, error) {
-	if osRelease == nil {
-		return nil, fmt.Errorf("OS release information required for vulnerability checking")
+// ubuntuStreamRegex, rpmStreamRegex, and suseStreamRegex extract the
+// release-stream marker from a distro package version: Ubuntu's per-series
+// "ubuntuN" tag, Azure Linux/Mariner's per-release ".azlN"/".cmN" dist tag,
+// and SUSE's ".suse.lpNNN" openSUSE Leap tag or bare numeric product-version
+// tag (e.g. ".150500") used by its CVRF advisories. The bare numeric
+// alternative requires at least 4 digits and is only matched against the
+// release field (the part of version after its last "-"), so it can't lock
+// onto an unrelated 4+-digit component of the upstream version instead of
+// the actual SUSE product code. Distros maintain these streams' backports
+// independently, so the same CVE can carry one FixedVersion per stream (e.g.
+// "1.2.3-4ubuntu1.3" on 20.04 vs "1.2.3-4ubuntu2.1" on 22.04).
+var (
+	ubuntuStreamRegex = regexp.MustCompile(`ubuntu(\d+)`)
+	rpmStreamRegex    = regexp.MustCompile(`\.(azl\d+|cm\d+)(?:\.|$)`)
+	suseStreamRegex   = regexp.MustCompile(`(?:^|\.)(suse\.lp\d+|\d{4,})(?:\.|$)`)
+)
+
+// streamSuffix returns the release-stream marker identifying which backport
+// lineage version belongs to, or "" if distro has no such concept or version
+// doesn't carry one.
+func streamSuffix(version string, distro OSDistro) string {
+	switch distro {
+	case DistroUbuntu:
+		if m := ubuntuStreamRegex.FindStringSubmatch(version); m != nil {
+			return "ubuntu" + m[1]
+		}
+	case DistroAzureLinux, DistroMariner:
+		if m := rpmStreamRegex.FindStringSubmatch(version); m != nil {
+			return m[1]
+		}
+	case DistroSUSE:
+		release := version
+		if idx := strings.LastIndex(version, "-"); idx != -1 {
+			release = version[idx+1:]
+		}
+		if m := suseStreamRegex.FindStringSubmatch(release); m != nil {
+			return m[1]
+		}
 	}
+	return ""
+}
 
-	var allVulns []types.Advisory
-	var err error
+// compareDistroVersions compares two version strings using distro's version
+// semantics, returning 0 if either fails to parse or distro isn't supported.
+func compareDistroVersions(a, b string, distro OSDistro) int {
+	switch distro {
+	case DistroUbuntu:
+		av, err := debversion.NewVersion(a)
+		if err != nil {
+			return 0
+		}
+		bv, err := debversion.NewVersion(b)
+		if err != nil {
+			return 0
+		}
+		return av.Compare(bv)
+	case DistroAzureLinux, DistroMariner, DistroSUSE:
+		return rpmversion.NewVersion(a).Compare(rpmversion.NewVersion(b))
+	default:
+		return 0
+	}
+}
 
+// fixedVersionsSpanStreams reports whether fixedVersions carry more than one
+// distinct release-stream suffix, meaning a fix chosen without knowing the
+// installed package's own stream can't be confirmed to apply to it.
+func fixedVersionsSpanStreams(fixedVersions []string, distro OSDistro) bool {
+	seen := ""
+	for _, fv := range fixedVersions {
+		stream := streamSuffix(fv, distro)
+		if stream == "" {
+			continue
+		}
+		if seen == "" {
+			seen = stream
+		} else if seen != stream {
+			return true
+		}
+	}
+	return false
+}
+
+// selectFixedVersion picks which of a CVE's parallel-stream FixedVersions
+// applies to pkgVersion (e.g. Ubuntu 22.04's "1.2.3-4ubuntu2.1" vs 20.04's
+// "1.2.3-4ubuntu1.3" backport of the same CVE): it prefers whichever fixed
+// versions share pkgVersion's stream marker, picking the highest if more than
+// one does. If none share pkgVersion's stream, it falls back to the highest
+// fix across every stream and reports crossStream=true, since that fix may
+// not actually apply to this package's lineage and needs manual
+// confirmation.
+func selectFixedVersion(pkgVersion string, fixedVersions []string, distro OSDistro) (best string, crossStream bool) {
+	if len(fixedVersions) == 0 {
+		return "", false
+	}
+
+	candidates := fixedVersions
+	if stream := streamSuffix(pkgVersion, distro); stream != "" {
+		var sameStream []string
+		for _, fv := range fixedVersions {
+			if streamSuffix(fv, distro) == stream {
+				sameStream = append(sameStream, fv)
+			}
+		}
+		if len(sameStream) > 0 {
+			candidates = sameStream
+		} else {
+			crossStream = true
+		}
+	} else {
+		crossStream = fixedVersionsSpanStreams(fixedVersions, distro)
+	}
+
+	for _, fv := range candidates {
+		if best == "" || compareDistroVersions(fv, best, distro) > 0 {
+			best = fv
+		}
+	}
+	return best, crossStream
+}
+
+// vulnerabilityStatus resolves the effective types.Status of vuln against an
+// installed package: FixedVersion only tells us a fix exists, not whether
+// it's actually been applied, so a vuln whose FixedVersion the installed
+// version already satisfies is reported as StatusFixed regardless of what
+// the advisory itself recorded. A not-affected advisory always wins, since
+// it overrides any (possibly stale) FixedVersion. Anything else falls back to
+// the advisory's own Status, or StatusAffected if the source left it unset.
+func vulnerabilityStatus(pkgVersion string, vuln types.Advisory, distro OSDistro) types.Status {
+	if vuln.Status == types.StatusNotAffected {
+		return types.StatusNotAffected
+	}
+	if vuln.FixedVersion != "" && isPackageFixed(pkgVersion, vuln.FixedVersion, distro) {
+		return types.StatusFixed
+	}
+	if vuln.Status != types.StatusUnknown {
+		return vuln.Status
+	}
+	return types.StatusAffected
+}
+
+// VulnFilter narrows the vulnerabilities checkPackageVulnerabilities reports.
+// A zero VulnFilter reports every non-fixed, non-not-affected vulnerability,
+// matching the unfiltered behavior of earlier versions of this tool.
+type VulnFilter struct {
+	// IgnoreStatuses drops advisories whose resolved status (see
+	// vulnerabilityStatus) matches any of these, e.g. StatusEndOfLife on a
+	// frozen host that won't receive further distro updates.
+	IgnoreStatuses []types.Status
+	// Severities, if non-empty, restricts results to advisories carrying one
+	// of these severities.
+	Severities []types.Severity
+}
+
+func (f VulnFilter) ignoresStatus(status types.Status) bool {
+	for _, ignored := range f.IgnoreStatuses {
+		if ignored == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (f VulnFilter) matchesSeverity(severity types.Severity) bool {
+	if len(f.Severities) == 0 {
+		return true
+	}
+	if severity == types.SeverityUnknown {
+		// A severity we couldn't resolve isn't evidence of low importance -
+		// don't let a missing rating silently drop the advisory from a
+		// --severity-filtered report.
+		return true
+	}
+	for _, want := range f.Severities {
+		if want == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatusList parses a comma-separated list of Trivy status names (e.g.
+// "end_of_life,will_not_fix") as used by the --ignore-status flag.
+func parseStatusList(value string) ([]types.Status, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var statuses []types.Status
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		status := types.NewStatus(name)
+		if status == types.StatusUnknown && name != "unknown" {
+			return nil, fmt.Errorf("unknown status %q (want one of: %s)", name, strings.Join(types.Statuses, ", "))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// parseSeverityList parses a comma-separated list of Trivy severity names
+// (e.g. "high,critical") as used by the --severity flag.
+func parseSeverityList(value string) ([]types.Severity, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var severities []types.Severity
+	for _, name := range strings.Split(value, ",") {
+		severity, err := types.NewSeverity(strings.ToUpper(strings.TrimSpace(name)))
+		if err != nil {
+			return nil, err
+		}
+		severities = append(severities, severity)
+	}
+	return severities, nil
+}
+
+// suseDistribution maps an os-release ID to the SUSE CVRF distribution it should
+// be scanned against, since enterprise and openSUSE advisories are tracked separately.
+func suseDistribution(id string) susecvrf.Distribution {
+	switch id {
+	case "opensuse", "opensuse-leap":
+		return susecvrf.OpenSUSE
+	default:
+		// sles, sled, suse-openstack-cloud all track against SUSE Enterprise Linux advisories.
+		return susecvrf.SUSEEnterpriseLinux
+	}
+}
+
+// vulnAdvisoriesByName queries the Trivy DB vulnerability source for the
+// given distro and returns every advisory recorded against pkgName, without
+// filtering for fixed status.
+func vulnAdvisoriesByName(pkgName string, osRelease *OSRelease) ([]types.Advisory, error) {
 	switch osRelease.Distro {
 	case DistroUbuntu:
-		vulnSrc := ubuntu.NewVulnSrc()
-		allVulns, err = vulnSrc.Get(osRelease.VersionID, pkg.Name)
+		return ubuntu.NewVulnSrc().Get(osRelease.VersionID, pkgName)
 	case DistroAzureLinux:
-		vulnSrc := azure.NewVulnSrc(azure.Azure)
-		allVulns, err = vulnSrc.Get(osRelease.VersionID, pkg.Name)
+		return azure.NewVulnSrc(azure.Azure).Get(osRelease.VersionID, pkgName)
 	case DistroMariner:
-		vulnSrc := azure.NewVulnSrc(azure.Mariner)
-		allVulns, err = vulnSrc.Get(osRelease.VersionID, pkg.Name)
+		return azure.NewVulnSrc(azure.Mariner).Get(osRelease.VersionID, pkgName)
+	case DistroSUSE:
+		return susecvrf.NewVulnSrc(suseDistribution(osRelease.ID)).Get(osRelease.VersionID, pkgName)
 	default:
 		return nil, fmt.Errorf("unsupported distribution: %s", osRelease.Distro)
 	}
+}
 
+// severitySource maps an OSDistro to the trivy-db source ID whose
+// VulnerabilityDetail carries that distro's severity rating, mirroring
+// vulnAdvisoriesByName's distro switch: each vulnsrc package records
+// FixedVersion on the types.Advisory it returns, but Severity is stored
+// separately, keyed by the vulnsrc's own source ID.
+func severitySource(distro OSDistro) (types.SourceID, bool) {
+	switch distro {
+	case DistroUbuntu:
+		return vulnsrcvuln.Ubuntu, true
+	case DistroAzureLinux:
+		return vulnsrcvuln.AzureLinux, true
+	case DistroMariner:
+		return vulnsrcvuln.CBLMariner, true
+	case DistroSUSE:
+		return vulnsrcvuln.SuseCVRF, true
+	default:
+		return "", false
+	}
+}
+
+// resolveSeverity looks up vulnID's severity as recorded by distro's
+// trivy-db source. types.Advisory never carries Severity - every vulnsrc
+// package puts it on a separate VulnerabilityDetail instead - so without this
+// lookup every advisory from every distro would compare equal to
+// types.SeverityUnknown and VulnFilter.matchesSeverity would drop them all
+// under any --severity filter. Returns types.SeverityUnknown if distro isn't
+// recognized or the source recorded no detail for vulnID.
+func resolveSeverity(vulnID string, distro OSDistro) types.Severity {
+	sourceID, ok := severitySource(distro)
+	if !ok {
+		return types.SeverityUnknown
+	}
+	details, err := (db.Config{}).GetVulnerabilityDetail(vulnID)
+	if err != nil {
+		return types.SeverityUnknown
+	}
+	return details[sourceID].Severity
+}
+
+// mergeAdvisories unions a package's own advisories with advisories recorded
+// against its source package, keeping the binary-specific record on a
+// VulnerabilityID collision since it's the more precise of the two.
+func mergeAdvisories(binaryVulns, sourceVulns []types.Advisory) []types.Advisory {
+	if len(sourceVulns) == 0 {
+		return binaryVulns
+	}
+	seen := make(map[string]bool, len(binaryVulns))
+	merged := make([]types.Advisory, 0, len(binaryVulns)+len(sourceVulns))
+	for _, vuln := range binaryVulns {
+		seen[vuln.VulnerabilityID] = true
+		merged = append(merged, vuln)
+	}
+	for _, vuln := range sourceVulns {
+		if seen[vuln.VulnerabilityID] {
+			continue
+		}
+		merged = append(merged, vuln)
+	}
+	return merged
+}
+
+// consolidateMultiStreamAdvisories collapses multiple Advisory records for
+// the same VulnerabilityID (one OVAL query can return one per maintained
+// release stream, e.g. Ubuntu 20.04 and 22.04 backports of the same CVE)
+// into a single record, using selectFixedVersion to pick the FixedVersion
+// that applies to pkgVersion's own stream. Records for a CVE not seen before
+// pass through unchanged. A cross-stream match (no stream shared
+// pkgVersion's lineage) is logged rather than silently trusted, since the
+// chosen fix may not actually apply to this package.
+func consolidateMultiStreamAdvisories(vulns []types.Advisory, pkgVersion string, distro OSDistro) []types.Advisory {
+	type group struct {
+		advisory      types.Advisory
+		fixedVersions []string
+	}
+
+	groups := make(map[string]*group, len(vulns))
+	order := make([]string, 0, len(vulns))
+	for _, vuln := range vulns {
+		g, ok := groups[vuln.VulnerabilityID]
+		if !ok {
+			g = &group{advisory: vuln}
+			groups[vuln.VulnerabilityID] = g
+			order = append(order, vuln.VulnerabilityID)
+		}
+		if vuln.FixedVersion != "" {
+			g.fixedVersions = append(g.fixedVersions, vuln.FixedVersion)
+		}
+	}
+
+	consolidated := make([]types.Advisory, 0, len(order))
+	for _, id := range order {
+		g := groups[id]
+		if len(g.fixedVersions) > 0 {
+			chosen, crossStream := selectFixedVersion(pkgVersion, g.fixedVersions, distro)
+			g.advisory.FixedVersion = chosen
+			if crossStream {
+				fmt.Printf("%s %s: %s fix matched across streams (%s) - verify manually\n", pkgVersion, id, chosen, strings.Join(g.fixedVersions, ", "))
+			}
+		}
+		consolidated = append(consolidated, g.advisory)
+	}
+	return consolidated
+}
+
+// checkPackageVulnerabilities checks for vulnerabilities in a given package
+// based on the OS distribution and version. Drops vulnerabilities that are
+// fixed (including those satisfied by the installed version even if the
+// advisory itself never recorded "fixed") or not_affected, and anything
+// matching filter's IgnoreStatuses/Severities. Everything else is returned,
+// including distro-specific terminal statuses like will_not_fix and
+// end_of_life, so callers can still distinguish and report on them.
+func checkPackageVulnerabilities(pkg OSPackage, osRelease *OSRelease, filter VulnFilter, source VulnSource) ([]types.Advisory, error) {
+	if osRelease == nil {
+		return nil, fmt.Errorf("OS release information required for vulnerability checking")
+	}
+	if source == nil {
+		source = trivyDBSource{}
+	}
+
+	allVulns, err := source.Advisories(pkg.Name, osRelease)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter out fixed vulnerabilities
+	// Ubuntu and Azure Linux/Mariner OVAL advisories are frequently recorded
+	// against the upstream source package (e.g. "openssl") rather than every
+	// binary it produces (e.g. "libssl3"); merge in whatever is recorded
+	// under the source name too, so a binary package inherits its source
+	// package's vulnerabilities the way Clair's Feature/parent model does.
+	// SourceName/SourceVersion come from the parser's "Source Packages"
+	// section (Ubuntu's Sources.gz binary->source index, or an RPM's
+	// %{SOURCERPM} field); a package with neither just checks its own name,
+	// same as before this lookup existed.
+	if pkg.SourceName != "" && pkg.SourceName != pkg.Name {
+		sourceVulns, err := source.Advisories(pkg.SourceName, osRelease)
+		if err != nil {
+			return nil, err
+		}
+		allVulns = mergeAdvisories(allVulns, sourceVulns)
+	}
+
+	allVulns = consolidateMultiStreamAdvisories(allVulns, pkg.Version, osRelease.Distro)
+
 	var activeVulns []types.Advisory
 	for _, vuln := range allVulns {
-		if !isPackageFixed(pkg.Version, vuln.FixedVersion, osRelease.Distro) {
-			activeVulns = append(activeVulns, vuln)
+		status := vulnerabilityStatus(pkg.Version, vuln, osRelease.Distro)
+		if status == types.StatusFixed || status == types.StatusNotAffected {
+			continue
+		}
+		if vuln.Severity == types.SeverityUnknown {
+			vuln.Severity = resolveSeverity(vuln.VulnerabilityID, osRelease.Distro)
+		}
+		if filter.ignoresStatus(status) || !filter.matchesSeverity(vuln.Severity) {
+			continue
 		}
+		activeVulns = append(activeVulns, vuln)
 	}
 
 	return activeVulns, nil
 }
 
+// writeSBOM renders the parsed result as an SBOM document in the requested
+// format and writes it to stdout.
+func writeSBOM(result *ParseResult, format outputFormat) {
+	var sbomFormat sbom.Format
+	switch format {
+	case formatSBOMCycloneDX:
+		sbomFormat = sbom.FormatCycloneDX
+	case formatSBOMSPDX:
+		sbomFormat = sbom.FormatSPDX
+	default:
+		fatalIfErr(fmt.Errorf("writeSBOM: unsupported format %q", format))
+	}
+
+	writer, err := sbom.NewWriter(sbomFormat)
+	fatalIfErr(err)
+	fatalIfErr(writer.Write(os.Stdout, buildSBOMDocument(result)))
+}
+
 func main() {
-	// Check command line arguments
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <file-path-or-url>\n", os.Args[0])
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+	runScan(os.Args[1:])
+}
+
+// runScan implements the default one-shot CLI mode: parse a single source
+// and print text, SBOM, or vulnerability findings to stdout.
+func runScan(args []string) {
+	fs := flag.NewFlagSet("oval-package-parser", flag.ExitOnError)
+	format := fs.String("format", string(formatText), "output format: text, sbom-cyclonedx, sbom-spdx, or copa")
+	concurrency := fs.Int("concurrency", runtime.NumCPU(), "number of packages to scan for vulnerabilities concurrently")
+	noCache := fs.Bool("no-cache", false, "always fetch and re-parse the source, ignoring the on-disk result cache")
+	cacheDir := fs.String("cache-dir", "", "directory for the parsed-result cache (default $XDG_CACHE_HOME/oval-package-parser)")
+	ignoreStatus := fs.String("ignore-status", "", "comma-separated advisory statuses to drop (e.g. end_of_life,will_not_fix)")
+	severity := fs.String("severity", "", "comma-separated severities to report (e.g. high,critical); default reports all")
+	sourceFlag := fs.String("source", "trivy", "vulnerability source: trivy (default) or ubuntu-cve (Ubuntu CVE Tracker, opt-in only - fetches the tracker's corpus live over HTTP)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [--format text|sbom-cyclonedx|sbom-spdx|copa] [--ignore-status ...] [--severity ...] [--source trivy|ubuntu-cve] <file-path-or-url>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s serve [--addr :8080]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Example: %s ./sample-ubuntu.txt\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Example: %s https://example.com/vhd-build-output.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Example: %s --format sbom-cyclonedx ./sample-ubuntu.txt\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	source := os.Args[1]
-	
-	p, err := NewParser()
+	outFormat, err := parseOutputFormat(*format)
 	fatalIfErr(err)
 
-	// Fetch content from source (file or URL)
-	reader, err := fetchContent(source)
+	ignoreStatuses, err := parseStatusList(*ignoreStatus)
 	fatalIfErr(err)
-	defer func() {
-		if closer, ok := reader.(io.Closer); ok {
-			closer.Close()
-		}
-	}()
+	severities, err := parseSeverityList(*severity)
+	fatalIfErr(err)
+	filter := VulnFilter{IgnoreStatuses: ignoreStatuses, Severities: severities}
 
-	// Parse the content
-	d, err := p.Parse(reader)
+	source := fs.Arg(0)
+
+	p, err := NewParser()
 	fatalIfErr(err)
 
+	var d *ParseResult
+	if *noCache {
+		// Fetch content from source (file or URL)
+		reader, err := fetchContent(source)
+		fatalIfErr(err)
+		defer func() {
+			if closer, ok := reader.(io.Closer); ok {
+				closer.Close()
+			}
+		}()
+
+		d, err = p.Parse(reader)
+		fatalIfErr(err)
+	} else {
+		cache, err := NewResultCache(*cacheDir)
+		fatalIfErr(err)
+		d, err = cache.FetchAndParse(p, source)
+		fatalIfErr(err)
+	}
+
+	if outFormat == formatSBOMCycloneDX || outFormat == formatSBOMSPDX {
+		writeSBOM(d, outFormat)
+		return
+	}
+
 	// Initialize Trivy vulnerability database
 	homeDir := os.Getenv("HOME")
 	fatalIfErr(db.Init(homeDir + "/.cache/trivy/db"))
 
+	if outFormat == formatCopa {
+		if d.OSRelease == nil {
+			fatalIfErr(fmt.Errorf("--format copa requires OS release information"))
+		}
+		vulnSource, err := resolveVulnSource(*sourceFlag, d.OSRelease.Distro)
+		fatalIfErr(err)
+		findings, err := scanPackages(context.Background(), d.Packages, d.OSRelease, *concurrency, filter, vulnSource)
+		fatalIfErr(err)
+		fatalIfErr(writeCopaManifest(os.Stdout, findings, d.OSRelease))
+		return
+	}
+
 	fmt.Println("=== Container Images ===")
 	for _, img := range d.ContainerImages {
-		fmt.Println(img.Name)
+		fmt.Println(img.String())
 	}
 
 	// Vulnerability scanning summary counters
@@ -156,37 +628,46 @@ func main() {
 	packagesWithVulns := 0
 	packagesWithFixableVulns := 0
 	packagesWithUnfixableVulns := 0
+	packagesWithDeferredVulns := 0
 	totalVulns := 0
 	fixableVulns := 0
 	unfixableVulns := 0
+	deferredVulns := 0
 
 	fmt.Println("\n=== Packages with Vulnerabilities ===")
 
 	if d.OSRelease == nil {
 		fmt.Println("Unable to check vulnerabilities (no OS release info)")
 	} else {
-		for _, pkg := range d.Packages {
-			vulns, err := checkPackageVulnerabilities(pkg, d.OSRelease)
-			if err != nil {
-				fmt.Printf("Package: %s %s - Error: %v\n", pkg.Name, pkg.Version, err)
-				continue
-			}
+		vulnSource, err := resolveVulnSource(*sourceFlag, d.OSRelease.Distro)
+		fatalIfErr(err)
+		findings, err := scanPackages(context.Background(), d.Packages, d.OSRelease, *concurrency, filter, vulnSource)
+		fatalIfErr(err)
+
+		for _, finding := range findings {
+			vulns := finding.vulns
 
 			// Only show packages with vulnerabilities
 			if len(vulns) > 0 {
-				fmt.Printf("Package: %s %s\n", pkg.Name, pkg.Version)
+				fmt.Printf("Package: %s %s\n", finding.pkg.Name, finding.pkg.Version)
 				packagesWithVulns++
 				totalVulns += len(vulns)
 
 				hasFixable := false
 				hasUnfixable := false
+				hasDeferred := false
 
 				for _, vuln := range vulns {
-					if vuln.FixedVersion != "" {
+					switch {
+					case vuln.FixedVersion != "":
 						fmt.Printf("  - %s (needs upgrade to: %s)\n", vuln.VulnerabilityID, vuln.FixedVersion)
 						fixableVulns++
 						hasFixable = true
-					} else {
+					case vuln.Status == types.StatusWillNotFix || vuln.Status == types.StatusFixDeferred || vuln.Status == types.StatusEndOfLife:
+						fmt.Printf("  - %s (%s, no fix available)\n", vuln.VulnerabilityID, (&vuln.Status).String())
+						deferredVulns++
+						hasDeferred = true
+					default:
 						fmt.Printf("  - %s (no fix available)\n", vuln.VulnerabilityID)
 						unfixableVulns++
 						hasUnfixable = true
@@ -199,6 +680,9 @@ func main() {
 				if hasUnfixable {
 					packagesWithUnfixableVulns++
 				}
+				if hasDeferred {
+					packagesWithDeferredVulns++
+				}
 
 				fmt.Println()
 			}
@@ -211,9 +695,11 @@ func main() {
 	fmt.Printf("Packages with vulnerabilities: %d\n", packagesWithVulns)
 	fmt.Printf("Packages with fixable vulnerabilities: %d\n", packagesWithFixableVulns)
 	fmt.Printf("Packages with unfixable vulnerabilities: %d\n", packagesWithUnfixableVulns)
+	fmt.Printf("Packages with deferred/EOL vulnerabilities: %d\n", packagesWithDeferredVulns)
 	fmt.Printf("Total vulnerabilities found: %d\n", totalVulns)
 	fmt.Printf("Fixable vulnerabilities: %d\n", fixableVulns)
 	fmt.Printf("Unfixable vulnerabilities: %d\n", unfixableVulns)
+	fmt.Printf("Deferred/EOL vulnerabilities: %d\n", deferredVulns)
 
 	if d.OSRelease != nil {
 		fmt.Printf("\n=== OS Info ===\n")
@@ -221,3 +707,30 @@ func main() {
 		fmt.Printf("OS: %s %s (%s)\n", d.OSRelease.Name, d.OSRelease.Version, d.OSRelease.VersionID)
 	}
 }
+
+// runServe implements the `serve` subcommand: a long-running HTTP scanner
+// suitable for AKS node-image CI gates, warming a single Trivy DB handle and
+// result cache that every request shares, and shutting down gracefully on
+// SIGTERM/SIGINT.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("oval-package-parser serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	concurrency := fs.Int("concurrency", runtime.NumCPU(), "number of packages to scan for vulnerabilities concurrently")
+	cacheDir := fs.String("cache-dir", "", "directory for the parsed-result cache (default $XDG_CACHE_HOME/oval-package-parser)")
+	fs.Parse(args)
+
+	homeDir := os.Getenv("HOME")
+	fatalIfErr(db.Init(homeDir + "/.cache/trivy/db"))
+
+	p, err := NewParser()
+	fatalIfErr(err)
+	cache, err := NewResultCache(*cacheDir)
+	fatalIfErr(err)
+
+	srv := NewServer(p, cache, *concurrency)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	fatalIfErr(Serve(ctx, *addr, srv))
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/aquasecurity/trivy-db/pkg/types"
+	susecvrf "github.com/aquasecurity/trivy-db/pkg/vulnsrc/suse-cvrf"
 	debversion "github.com/knqyf263/go-deb-version"
 	rpmversion "github.com/knqyf263/go-rpm-version"
 )
@@ -114,7 +115,7 @@ func TestFetchContent_LocalFile_NotFound(t *testing.T) {
 
 func TestFetchContent_URL(t *testing.T) {
 	testContent := "=== Installed Packages Begin ===\ntest-package 1.0\n=== Installed Packages End ==="
-	
+
 	// Create a test HTTP server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -209,7 +210,7 @@ func TestIsPackageFixed_Ubuntu(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result := isPackageFixed(tt.pkgVersion, tt.fixedVersion, DistroUbuntu)
 			if result != tt.expected {
-				t.Errorf("isPackageFixed(%q, %q, Ubuntu) = %v, expected %v", 
+				t.Errorf("isPackageFixed(%q, %q, Ubuntu) = %v, expected %v",
 					tt.pkgVersion, tt.fixedVersion, result, tt.expected)
 			}
 		})
@@ -259,7 +260,7 @@ func TestIsPackageFixed_AzureLinux(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result := isPackageFixed(tt.pkgVersion, tt.fixedVersion, DistroAzureLinux)
 			if result != tt.expected {
-				t.Errorf("isPackageFixed(%q, %q, AzureLinux) = %v, expected %v", 
+				t.Errorf("isPackageFixed(%q, %q, AzureLinux) = %v, expected %v",
 					tt.pkgVersion, tt.fixedVersion, result, tt.expected)
 			}
 		})
@@ -303,13 +304,193 @@ func TestIsPackageFixed_Mariner(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result := isPackageFixed(tt.pkgVersion, tt.fixedVersion, DistroMariner)
 			if result != tt.expected {
-				t.Errorf("isPackageFixed(%q, %q, Mariner) = %v, expected %v", 
+				t.Errorf("isPackageFixed(%q, %q, Mariner) = %v, expected %v",
+					tt.pkgVersion, tt.fixedVersion, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPackageFixed_SUSE(t *testing.T) {
+	tests := []struct {
+		name         string
+		pkgVersion   string
+		fixedVersion string
+		expected     bool
+	}{
+		{
+			name:         "Package version equals fixed version",
+			pkgVersion:   "2.38-10.150500",
+			fixedVersion: "2.38-10.150500",
+			expected:     true,
+		},
+		{
+			name:         "Package version newer than fixed version",
+			pkgVersion:   "2.38-11.150500",
+			fixedVersion: "2.38-10.150500",
+			expected:     true,
+		},
+		{
+			name:         "Package version older than fixed version",
+			pkgVersion:   "2.38-9.150500",
+			fixedVersion: "2.38-10.150500",
+			expected:     false,
+		},
+		{
+			name:         "No fixed version available",
+			pkgVersion:   "2.38-10.150500",
+			fixedVersion: "",
+			expected:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isPackageFixed(tt.pkgVersion, tt.fixedVersion, DistroSUSE)
+			if result != tt.expected {
+				t.Errorf("isPackageFixed(%q, %q, SUSE) = %v, expected %v",
 					tt.pkgVersion, tt.fixedVersion, result, tt.expected)
 			}
 		})
 	}
 }
 
+// TestSelectFixedVersion_Ubuntu mirrors TestIsPackageFixed_Ubuntu but covers
+// the multi-stream FixedVersions path: a CVE backported independently on
+// Ubuntu 20.04 ("ubuntu1" stream) and 22.04 ("ubuntu2" stream).
+func TestSelectFixedVersion_Ubuntu(t *testing.T) {
+	fixedVersions := []string{"1.2.3-4ubuntu1.3", "1.2.3-4ubuntu2.1"}
+
+	tests := []struct {
+		name          string
+		pkgVersion    string
+		expectedFixed string
+		expectedCross bool
+	}{
+		{
+			name:          "20.04 package picks the ubuntu1 stream fix",
+			pkgVersion:    "1.2.3-4ubuntu1.1",
+			expectedFixed: "1.2.3-4ubuntu1.3",
+			expectedCross: false,
+		},
+		{
+			name:          "22.04 package picks the ubuntu2 stream fix",
+			pkgVersion:    "1.2.3-4ubuntu2.0",
+			expectedFixed: "1.2.3-4ubuntu2.1",
+			expectedCross: false,
+		},
+		{
+			name:          "unrecognized stream falls back to the highest fix, flagged cross-stream",
+			pkgVersion:    "1.2.3-4",
+			expectedFixed: "1.2.3-4ubuntu2.1",
+			expectedCross: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixed, cross := selectFixedVersion(tt.pkgVersion, fixedVersions, DistroUbuntu)
+			if fixed != tt.expectedFixed {
+				t.Errorf("selectFixedVersion() fixed = %q, want %q", fixed, tt.expectedFixed)
+			}
+			if cross != tt.expectedCross {
+				t.Errorf("selectFixedVersion() crossStream = %v, want %v", cross, tt.expectedCross)
+			}
+		})
+	}
+}
+
+// TestSelectFixedVersion_AzureLinux covers the Azure Linux/Mariner dist-tag
+// stream marker (".azl3" vs ".cm2") instead of Ubuntu's "ubuntuN" tag.
+func TestSelectFixedVersion_AzureLinux(t *testing.T) {
+	fixedVersions := []string{"1.2.3-5.azl3", "1.2.3-8.cm2"}
+
+	fixed, cross := selectFixedVersion("1.2.3-4.azl3", fixedVersions, DistroAzureLinux)
+	if fixed != "1.2.3-5.azl3" || cross {
+		t.Errorf("selectFixedVersion() = (%q, %v), want (%q, false)", fixed, cross, "1.2.3-5.azl3")
+	}
+
+	fixed, cross = selectFixedVersion("1.2.3-4.cm2", fixedVersions, DistroMariner)
+	if fixed != "1.2.3-8.cm2" || cross {
+		t.Errorf("selectFixedVersion() = (%q, %v), want (%q, false)", fixed, cross, "1.2.3-8.cm2")
+	}
+}
+
+// TestSelectFixedVersion_SUSE covers SUSE's two stream-marker forms: the
+// ".suse.lpNNN" openSUSE Leap backport tag and the bare numeric product-
+// version tag SUSE's CVRF advisories use (e.g. ".150500").
+func TestSelectFixedVersion_SUSE(t *testing.T) {
+	fixedVersions := []string{"1.2.3-5.suse.lp154", "1.2.3-9.150500"}
+
+	fixed, cross := selectFixedVersion("1.2.3-4.suse.lp154", fixedVersions, DistroSUSE)
+	if fixed != "1.2.3-5.suse.lp154" || cross {
+		t.Errorf("selectFixedVersion() = (%q, %v), want (%q, false)", fixed, cross, "1.2.3-5.suse.lp154")
+	}
+
+	fixed, cross = selectFixedVersion("1.2.3-4.150500", fixedVersions, DistroSUSE)
+	if fixed != "1.2.3-9.150500" || cross {
+		t.Errorf("selectFixedVersion() = (%q, %v), want (%q, false)", fixed, cross, "1.2.3-9.150500")
+	}
+
+	// The upstream version segment can itself contain a 4+-digit run (e.g.
+	// a "2023" calendar-versioned component); streamSuffix must not mistake
+	// it for the SUSE product-code stream tag living in the release field.
+	if stream := streamSuffix("1.2023.4-150400.3.9.1", DistroSUSE); stream != "150400" {
+		t.Errorf("streamSuffix() = %q, want %q", stream, "150400")
+	}
+}
+
+// TestConsolidateMultiStreamAdvisories verifies multiple Advisory records for
+// the same CVE (one per maintained stream) collapse into a single advisory
+// carrying whichever FixedVersion matches the installed package's own
+// stream, so a CVE is never reported both fixed and active at once.
+func TestConsolidateMultiStreamAdvisories(t *testing.T) {
+	vulns := []types.Advisory{
+		createMockAdvisory("CVE-2024-0001", "1.2.3-4ubuntu1.3"), // 20.04 stream
+		createMockAdvisory("CVE-2024-0001", "1.2.3-4ubuntu2.1"), // 22.04 stream
+		createMockAdvisory("CVE-2024-9999", "1.2.3-4ubuntu1.0"), // unrelated single-stream CVE
+	}
+
+	consolidated := consolidateMultiStreamAdvisories(vulns, "1.2.3-4ubuntu1.1", DistroUbuntu)
+
+	if len(consolidated) != 2 {
+		t.Fatalf("len(consolidated) = %d, want 2", len(consolidated))
+	}
+
+	byID := make(map[string]types.Advisory, len(consolidated))
+	for _, v := range consolidated {
+		byID[v.VulnerabilityID] = v
+	}
+
+	if got := byID["CVE-2024-0001"].FixedVersion; got != "1.2.3-4ubuntu1.3" {
+		t.Errorf("CVE-2024-0001 FixedVersion = %q, want %q (the installed package's own stream)", got, "1.2.3-4ubuntu1.3")
+	}
+	if got := byID["CVE-2024-9999"].FixedVersion; got != "1.2.3-4ubuntu1.0" {
+		t.Errorf("CVE-2024-9999 FixedVersion = %q, want unchanged %q", got, "1.2.3-4ubuntu1.0")
+	}
+}
+
+// TestConsolidateMultiStreamAdvisories_SingleFixedVersionIsNotDropped covers
+// a CVE with a "needed" (unfixed) record on one stream and a fix on another:
+// since only one of the two records carries a FixedVersion, the group must
+// still surface that fix rather than leaving the first-seen, unfixed record
+// as the representative advisory.
+func TestConsolidateMultiStreamAdvisories_SingleFixedVersionIsNotDropped(t *testing.T) {
+	vulns := []types.Advisory{
+		createMockAdvisory("CVE-2024-0002", ""),                 // 20.04 stream, still needed
+		createMockAdvisory("CVE-2024-0002", "1.2.3-4ubuntu2.1"), // 22.04 stream, fixed
+	}
+
+	consolidated := consolidateMultiStreamAdvisories(vulns, "1.2.3-4ubuntu2.0", DistroUbuntu)
+
+	if len(consolidated) != 1 {
+		t.Fatalf("len(consolidated) = %d, want 1", len(consolidated))
+	}
+	if got := consolidated[0].FixedVersion; got != "1.2.3-4ubuntu2.1" {
+		t.Errorf("FixedVersion = %q, want %q (the single recorded fix must not be dropped)", got, "1.2.3-4ubuntu2.1")
+	}
+}
+
 func TestIsPackageFixed_UnsupportedDistro(t *testing.T) {
 	result := isPackageFixed("1.2.3", "1.2.4", DistroUnknown)
 	if result != false {
@@ -410,7 +591,7 @@ func TestCheckPackageVulnerabilities_NoOSRelease(t *testing.T) {
 		Version: "1.0.0",
 	}
 
-	_, err := checkPackageVulnerabilities(pkg, nil)
+	_, err := checkPackageVulnerabilities(pkg, nil, VulnFilter{}, nil)
 	if err == nil {
 		t.Error("Expected error when OSRelease is nil, got nil")
 	}
@@ -419,6 +600,27 @@ func TestCheckPackageVulnerabilities_NoOSRelease(t *testing.T) {
 	}
 }
 
+func TestSUSEDistribution(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected susecvrf.Distribution
+	}{
+		{"opensuse", susecvrf.OpenSUSE},
+		{"opensuse-leap", susecvrf.OpenSUSE},
+		{"sles", susecvrf.SUSEEnterpriseLinux},
+		{"sled", susecvrf.SUSEEnterpriseLinux},
+		{"suse-openstack-cloud", susecvrf.SUSEEnterpriseLinux},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := suseDistribution(tt.id); got != tt.expected {
+				t.Errorf("suseDistribution(%q) = %v, want %v", tt.id, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestCheckPackageVulnerabilities_UnsupportedDistro(t *testing.T) {
 	pkg := OSPackage{
 		Name:    "test-package",
@@ -428,7 +630,7 @@ func TestCheckPackageVulnerabilities_UnsupportedDistro(t *testing.T) {
 		Distro: DistroUnknown,
 	}
 
-	_, err := checkPackageVulnerabilities(pkg, osRelease)
+	_, err := checkPackageVulnerabilities(pkg, osRelease, VulnFilter{}, nil)
 	if err == nil {
 		t.Error("Expected error for unsupported distribution, got nil")
 	}
@@ -448,10 +650,10 @@ func createMockAdvisory(vulnID, fixedVersion string) types.Advisory {
 // Test the filtering logic with mock data
 func TestVulnerabilityFiltering(t *testing.T) {
 	tests := []struct {
-		name            string
-		pkgVersion      string
-		advisories      []types.Advisory
-		distro          OSDistro
+		name             string
+		pkgVersion       string
+		advisories       []types.Advisory
+		distro           OSDistro
 		expectedFiltered int
 	}{
 		{
@@ -461,7 +663,7 @@ func TestVulnerabilityFiltering(t *testing.T) {
 				createMockAdvisory("CVE-2023-1234", "1.2.3-4ubuntu1"), // Fixed
 				createMockAdvisory("CVE-2023-5678", "1.2.3-3ubuntu1"), // Fixed
 			},
-			distro:          DistroUbuntu,
+			distro:           DistroUbuntu,
 			expectedFiltered: 0,
 		},
 		{
@@ -471,7 +673,7 @@ func TestVulnerabilityFiltering(t *testing.T) {
 				createMockAdvisory("CVE-2023-1234", "1.2.3-4ubuntu2"), // Active
 				createMockAdvisory("CVE-2023-5678", "1.2.3-3ubuntu1"), // Fixed
 			},
-			distro:          DistroUbuntu,
+			distro:           DistroUbuntu,
 			expectedFiltered: 1,
 		},
 		{
@@ -481,7 +683,7 @@ func TestVulnerabilityFiltering(t *testing.T) {
 				createMockAdvisory("CVE-2023-1234", ""), // No fix
 				createMockAdvisory("CVE-2023-5678", ""), // No fix
 			},
-			distro:          DistroAzureLinux,
+			distro:           DistroAzureLinux,
 			expectedFiltered: 2,
 		},
 		{
@@ -492,7 +694,7 @@ func TestVulnerabilityFiltering(t *testing.T) {
 				createMockAdvisory("CVE-2023-5678", "1.2.3-3.azl3"), // Fixed
 				createMockAdvisory("CVE-2023-9999", ""),             // No fix
 			},
-			distro:          DistroAzureLinux,
+			distro:           DistroAzureLinux,
 			expectedFiltered: 2,
 		},
 	}
@@ -514,6 +716,214 @@ func TestVulnerabilityFiltering(t *testing.T) {
 	}
 }
 
+// TestVulnerabilityStatus covers every value of Trivy's Status enum, mirroring
+// TestVulnerabilityFiltering but exercising vulnerabilityStatus's resolution
+// logic directly rather than just the version-comparison half of it.
+func TestVulnerabilityStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		pkgVersion string
+		advisory   types.Advisory
+		distro     OSDistro
+		expected   types.Status
+	}{
+		{
+			name:       "unknown status with no fix is affected",
+			pkgVersion: "1.2.3-4ubuntu1",
+			advisory:   types.Advisory{Status: types.StatusUnknown},
+			distro:     DistroUbuntu,
+			expected:   types.StatusAffected,
+		},
+		{
+			name:       "not_affected always wins, even with a fixed version set",
+			pkgVersion: "1.2.3-4ubuntu1",
+			advisory:   types.Advisory{Status: types.StatusNotAffected, FixedVersion: "1.2.3-3ubuntu1"},
+			distro:     DistroUbuntu,
+			expected:   types.StatusNotAffected,
+		},
+		{
+			name:       "affected with unsatisfied fixed version stays affected",
+			pkgVersion: "1.2.3-4ubuntu1",
+			advisory:   types.Advisory{Status: types.StatusAffected, FixedVersion: "1.2.3-5ubuntu1"},
+			distro:     DistroUbuntu,
+			expected:   types.StatusAffected,
+		},
+		{
+			name:       "fixed version satisfied by installed version resolves to fixed",
+			pkgVersion: "1.2.3-4ubuntu2",
+			advisory:   types.Advisory{Status: types.StatusAffected, FixedVersion: "1.2.3-4ubuntu1"},
+			distro:     DistroUbuntu,
+			expected:   types.StatusFixed,
+		},
+		{
+			name:       "under_investigation with no fix yet",
+			pkgVersion: "1.2.3-4.azl3",
+			advisory:   types.Advisory{Status: types.StatusUnderInvestigation},
+			distro:     DistroAzureLinux,
+			expected:   types.StatusUnderInvestigation,
+		},
+		{
+			name:       "will_not_fix is reported as its own status, not silently unfixed",
+			pkgVersion: "1.2.3-4.azl3",
+			advisory:   types.Advisory{Status: types.StatusWillNotFix},
+			distro:     DistroAzureLinux,
+			expected:   types.StatusWillNotFix,
+		},
+		{
+			name:       "fix_deferred is reported as its own status",
+			pkgVersion: "1.2.3-4.azl3",
+			advisory:   types.Advisory{Status: types.StatusFixDeferred},
+			distro:     DistroAzureLinux,
+			expected:   types.StatusFixDeferred,
+		},
+		{
+			name:       "end_of_life is reported as its own status",
+			pkgVersion: "1.2.3-4.azl3",
+			advisory:   types.Advisory{Status: types.StatusEndOfLife},
+			distro:     DistroAzureLinux,
+			expected:   types.StatusEndOfLife,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := vulnerabilityStatus(tt.pkgVersion, tt.advisory, tt.distro)
+			if got != tt.expected {
+				t.Errorf("vulnerabilityStatus() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestVulnFilter_IgnoresStatus verifies VulnFilter.IgnoreStatuses, used by
+// the --ignore-status flag to drop statuses like end_of_life on frozen hosts.
+func TestVulnFilter_IgnoresStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter VulnFilter
+		status types.Status
+		want   bool
+	}{
+		{"no filter ignores nothing", VulnFilter{}, types.StatusEndOfLife, false},
+		{"matching status is ignored", VulnFilter{IgnoreStatuses: []types.Status{types.StatusEndOfLife}}, types.StatusEndOfLife, true},
+		{"non-matching status is not ignored", VulnFilter{IgnoreStatuses: []types.Status{types.StatusEndOfLife}}, types.StatusWillNotFix, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.ignoresStatus(tt.status); got != tt.want {
+				t.Errorf("ignoresStatus(%v) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVulnFilter_MatchesSeverity verifies VulnFilter.Severities, used by the
+// --severity flag to restrict reporting to, e.g., high and critical.
+func TestVulnFilter_MatchesSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   VulnFilter
+		severity types.Severity
+		want     bool
+	}{
+		{"empty allow-list matches everything", VulnFilter{}, types.SeverityLow, true},
+		{"severity in allow-list matches", VulnFilter{Severities: []types.Severity{types.SeverityHigh, types.SeverityCritical}}, types.SeverityCritical, true},
+		{"severity not in allow-list does not match", VulnFilter{Severities: []types.Severity{types.SeverityHigh, types.SeverityCritical}}, types.SeverityLow, false},
+		{"unresolved severity matches despite an allow-list", VulnFilter{Severities: []types.Severity{types.SeverityHigh, types.SeverityCritical}}, types.SeverityUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matchesSeverity(tt.severity); got != tt.want {
+				t.Errorf("matchesSeverity(%v) = %v, want %v", tt.severity, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeAdvisories_BinaryInheritsSourceVulnerability verifies a binary
+// package like libssl3, whose SourceName resolves to "openssl", picks up an
+// advisory recorded only against the source package name.
+func TestMergeAdvisories_BinaryInheritsSourceVulnerability(t *testing.T) {
+	pkg := OSPackage{Name: "libssl3", Version: "3.0.2-0ubuntu1.10", SourceName: "openssl", SourceVersion: "3.0.2-0ubuntu1.10"}
+
+	binaryVulns := []types.Advisory{} // Trivy DB has no advisories keyed on the binary name
+	sourceVulns := []types.Advisory{
+		createMockAdvisory("CVE-2023-1234", "3.0.2-0ubuntu1.12"),
+	}
+
+	merged := mergeAdvisories(binaryVulns, sourceVulns)
+	if len(merged) != 1 || merged[0].VulnerabilityID != "CVE-2023-1234" {
+		t.Fatalf("mergeAdvisories() = %+v, want [CVE-2023-1234]", merged)
+	}
+
+	// The merged advisory should be evaluated against the installed binary
+	// version exactly like a binary-native advisory would be.
+	if isPackageFixed(pkg.Version, merged[0].FixedVersion, DistroUbuntu) {
+		t.Error("expected inherited advisory to still be active against the installed version")
+	}
+}
+
+// TestMergeAdvisories_BinaryRecordWins verifies that when the same CVE is
+// recorded against both the binary and its source, the binary-specific
+// record (not the source one) survives the merge.
+func TestMergeAdvisories_BinaryRecordWins(t *testing.T) {
+	binaryVulns := []types.Advisory{createMockAdvisory("CVE-2023-1234", "3.0.2-0ubuntu1.12")}
+	sourceVulns := []types.Advisory{createMockAdvisory("CVE-2023-1234", "3.0.2-0ubuntu1.99")}
+
+	merged := mergeAdvisories(binaryVulns, sourceVulns)
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+	if merged[0].FixedVersion != "3.0.2-0ubuntu1.12" {
+		t.Errorf("FixedVersion = %q, want the binary-specific record's %q", merged[0].FixedVersion, "3.0.2-0ubuntu1.12")
+	}
+}
+
+// TestMergeAdvisories_NoSourceVulns verifies a package with no source-level
+// advisories is returned unchanged (and not even reallocated).
+func TestMergeAdvisories_NoSourceVulns(t *testing.T) {
+	binaryVulns := []types.Advisory{createMockAdvisory("CVE-2023-1234", "1.2.3")}
+	if got := mergeAdvisories(binaryVulns, nil); len(got) != 1 || got[0].VulnerabilityID != "CVE-2023-1234" {
+		t.Errorf("mergeAdvisories() = %+v, want %+v unchanged", got, binaryVulns)
+	}
+}
+
+func TestParseStatusList(t *testing.T) {
+	got, err := parseStatusList("end_of_life, will_not_fix")
+	if err != nil {
+		t.Fatalf("parseStatusList() error = %v", err)
+	}
+	want := []types.Status{types.StatusEndOfLife, types.StatusWillNotFix}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseStatusList() = %v, want %v", got, want)
+	}
+
+	if _, err := parseStatusList("not-a-real-status"); err == nil {
+		t.Error("expected error for unknown status, got nil")
+	}
+
+	if got, err := parseStatusList(""); err != nil || got != nil {
+		t.Errorf("parseStatusList(\"\") = (%v, %v), want (nil, nil)", got, err)
+	}
+}
+
+func TestParseSeverityList(t *testing.T) {
+	got, err := parseSeverityList("high,critical")
+	if err != nil {
+		t.Fatalf("parseSeverityList() error = %v", err)
+	}
+	want := []types.Severity{types.SeverityHigh, types.SeverityCritical}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseSeverityList() = %v, want %v", got, want)
+	}
+
+	if _, err := parseSeverityList("not-a-real-severity"); err == nil {
+		t.Error("expected error for unknown severity, got nil")
+	}
+}
+
 // Integration test that validates the complete flow without external dependencies
 func TestVulnerabilityWorkflow(t *testing.T) {
 	// Test package data
@@ -534,9 +944,9 @@ func TestVulnerabilityWorkflow(t *testing.T) {
 			t.Logf("Function panicked as expected without Trivy DB: %v", r)
 		}
 	}()
-	
-	_, err := checkPackageVulnerabilities(pkg, osRelease)
-	
+
+	_, err := checkPackageVulnerabilities(pkg, osRelease, VulnFilter{}, nil)
+
 	// We expect this to fail since we don't have Trivy DB initialized in tests
 	// But it should fail at the vulnerability source level, not due to our code
 	if err == nil {
@@ -584,4 +994,4 @@ func BenchmarkIsPackageFixed_AzureLinux(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		isPackageFixed(pkgVersion, fixedVersion, distro)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// scanDurationBuckets are the histogram bucket boundaries (in seconds) for
+// ovalpp_scan_duration_seconds. VHD inventories typically scan in a few
+// seconds; the upper buckets cover slow cold starts against a cold Trivy DB.
+var scanDurationBuckets = []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300}
+
+type vulnMetricKey struct {
+	distro  string
+	fixable string
+}
+
+// Metrics accumulates the Prometheus counters and histogram emitted by
+// serve mode across every request handled by one warmed server.
+type Metrics struct {
+	mu sync.Mutex
+
+	packagesScanned map[string]int
+	vulnsFound      map[vulnMetricKey]int
+	scanDurations   []float64
+}
+
+// NewMetrics creates an empty Metrics ready to be shared across requests.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		packagesScanned: make(map[string]int),
+		vulnsFound:      make(map[vulnMetricKey]int),
+	}
+}
+
+// ObservePackagesScanned records that n packages belonging to distro were
+// scanned for vulnerabilities.
+func (m *Metrics) ObservePackagesScanned(distro string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.packagesScanned[distro] += n
+}
+
+// ObserveVulnsFound records that n vulnerabilities were found for distro,
+// split by whether a fix is available.
+func (m *Metrics) ObserveVulnsFound(distro string, fixable bool, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vulnsFound[vulnMetricKey{distro: distro, fixable: strconv.FormatBool(fixable)}] += n
+}
+
+// ObserveScanDuration records the wall-clock time a /scan request took.
+func (m *Metrics) ObserveScanDuration(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scanDurations = append(m.scanDurations, d.Seconds())
+}
+
+// Render writes the accumulated metrics in Prometheus text exposition
+// format, sorting label combinations so output is stable across calls.
+func (m *Metrics) Render(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP ovalpp_packages_scanned_total Number of packages scanned for vulnerabilities.")
+	fmt.Fprintln(w, "# TYPE ovalpp_packages_scanned_total counter")
+	distros := make([]string, 0, len(m.packagesScanned))
+	for distro := range m.packagesScanned {
+		distros = append(distros, distro)
+	}
+	sort.Strings(distros)
+	for _, distro := range distros {
+		fmt.Fprintf(w, "ovalpp_packages_scanned_total{distro=%q} %d\n", distro, m.packagesScanned[distro])
+	}
+
+	fmt.Fprintln(w, "# HELP ovalpp_vulns_found_total Number of vulnerabilities found, labeled by whether a fix is available.")
+	fmt.Fprintln(w, "# TYPE ovalpp_vulns_found_total counter")
+	keys := make([]vulnMetricKey, 0, len(m.vulnsFound))
+	for key := range m.vulnsFound {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].distro != keys[j].distro {
+			return keys[i].distro < keys[j].distro
+		}
+		return keys[i].fixable < keys[j].fixable
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "ovalpp_vulns_found_total{distro=%q,fixable=%q} %d\n", key.distro, key.fixable, m.vulnsFound[key])
+	}
+
+	fmt.Fprintln(w, "# HELP ovalpp_scan_duration_seconds Duration of /scan requests.")
+	fmt.Fprintln(w, "# TYPE ovalpp_scan_duration_seconds histogram")
+	counts := make([]int, len(scanDurationBuckets))
+	var sum float64
+	for _, d := range m.scanDurations {
+		sum += d
+		for i, bound := range scanDurationBuckets {
+			if d <= bound {
+				counts[i]++
+			}
+		}
+	}
+	for i, bound := range scanDurationBuckets {
+		fmt.Fprintf(w, "ovalpp_scan_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), counts[i])
+	}
+	fmt.Fprintf(w, "ovalpp_scan_duration_seconds_bucket{le=\"+Inf\"} %d\n", len(m.scanDurations))
+	fmt.Fprintf(w, "ovalpp_scan_duration_seconds_sum %g\n", sum)
+	fmt.Fprintf(w, "ovalpp_scan_duration_seconds_count %d\n", len(m.scanDurations))
+	return nil
+}
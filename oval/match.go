@@ -0,0 +1,193 @@
+package oval
+
+import (
+	"strings"
+
+	debversion "github.com/knqyf263/go-deb-version"
+	rpmversion "github.com/knqyf263/go-rpm-version"
+)
+
+// Match evaluates idx's definitions against packages and returns one Finding
+// for every installed package whose version falls inside a definition's
+// vulnerable range.
+//
+// Evaluation is necessarily approximate for multi-package criteria (e.g. a
+// definition that also checks a running kernel version): under an OR, a leaf
+// whose check is for a different package than the one being matched is
+// treated as not satisfied, rather than consulting the rest of the installed
+// inventory. Under an AND, an operand that doesn't reference the package at
+// all - the platform/release criterion Canonical, Red Hat, and SUSE feeds
+// wrap their package OR in (e.g. "this Ubuntu release" AND "one of these
+// packages is vulnerable") - is instead treated as satisfied for the host,
+// since Match only ever evaluates one installed package's version at a time
+// and has no basis to evaluate a release predicate either way. See
+// evalNode's isOR handling.
+func Match(packages []Package, idx *Index) []Finding {
+	var findings []Finding
+
+	for _, pkg := range packages {
+		key := strings.ToLower(pkg.Distro) + "/" + pkg.Name
+		for _, def := range idx.definitions[key] {
+			fixedVersion, vulnerable := evaluate(def.criteria, idx.checks, pkg)
+			if !vulnerable {
+				continue
+			}
+			findings = append(findings, Finding{
+				Package:      pkg.Name,
+				DefinitionID: def.id,
+				CVEs:         def.cves,
+				Severity:     def.severity,
+				FixedVersion: fixedVersion,
+			})
+		}
+	}
+
+	return findings
+}
+
+// evaluate walks tree for pkg, returning whether pkg is vulnerable per the
+// criteria and, if so, the fixed version taken from whichever "less than"
+// check it satisfied.
+func evaluate(tree criteriaTree, checks map[string]check, pkg Package) (string, bool) {
+	var fixedVersion string
+	vulnerable := evalNode(tree, checks, pkg, &fixedVersion)
+	return fixedVersion, vulnerable
+}
+
+func evalNode(node criteriaTree, checks map[string]check, pkg Package, fixedVersion *string) bool {
+	isOR := node.operator == "OR"
+
+	var results []bool
+	relevant := false
+	for _, leaf := range node.leaves {
+		if !isOR && !leafReferencesPackage(leaf, checks, pkg.Name) {
+			continue
+		}
+		relevant = true
+		results = append(results, evalLeaf(leaf, checks, pkg, fixedVersion))
+	}
+	for _, child := range node.children {
+		if !isOR && !treeReferencesPackage(child, checks, pkg.Name) {
+			continue
+		}
+		relevant = true
+		results = append(results, evalNode(child, checks, pkg, fixedVersion))
+	}
+
+	var combined bool
+	if !isOR && !relevant {
+		// Every operand of this AND was a platform/release (or other-package)
+		// criterion that doesn't bear on pkg: vacuously satisfied.
+		combined = true
+	} else {
+		combined = combine(node.operator, results)
+	}
+	if node.negate {
+		combined = !combined
+	}
+	return combined
+}
+
+// leafReferencesPackage reports whether leaf's resolved check tests name.
+func leafReferencesPackage(leaf criterionLeaf, checks map[string]check, name string) bool {
+	c, ok := checks[leaf.testID]
+	return ok && c.name == name
+}
+
+// treeReferencesPackage reports whether any leaf under tree, at any depth,
+// tests name - used to tell a platform/release AND-operand (which doesn't
+// mention the package being matched at all) apart from the package OR it
+// wraps.
+func treeReferencesPackage(tree criteriaTree, checks map[string]check, name string) bool {
+	for _, leaf := range tree.leaves {
+		if leafReferencesPackage(leaf, checks, name) {
+			return true
+		}
+	}
+	for _, child := range tree.children {
+		if treeReferencesPackage(child, checks, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// combine applies an OVAL criteria operator to its children's results.
+// Anything other than "OR" - including an empty/unrecognized operator -
+// falls back to "AND", the spec's stricter combinator.
+func combine(operator string, results []bool) bool {
+	if len(results) == 0 {
+		return false
+	}
+	if operator == "OR" {
+		for _, r := range results {
+			if r {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range results {
+		if !r {
+			return false
+		}
+	}
+	return true
+}
+
+func evalLeaf(leaf criterionLeaf, checks map[string]check, pkg Package, fixedVersion *string) bool {
+	c, ok := checks[leaf.testID]
+	if !ok || c.name != pkg.Name {
+		return leaf.negate
+	}
+
+	matched := compareVersion(c.format, pkg.Version, c.operation, c.version)
+	if matched && c.operation == "less than" {
+		*fixedVersion = c.version
+	}
+	if leaf.negate {
+		matched = !matched
+	}
+	return matched
+}
+
+// compareVersion reports whether installed satisfies operation against
+// target, comparing with rpmvercmp semantics for RPM families and dpkg
+// `--compare-versions` semantics for Ubuntu/Debian.
+func compareVersion(format packageFormat, installed, operation, target string) bool {
+	var cmp int
+
+	switch format {
+	case formatDPKG:
+		installedVer, err := debversion.NewVersion(installed)
+		if err != nil {
+			return false
+		}
+		targetVer, err := debversion.NewVersion(target)
+		if err != nil {
+			return false
+		}
+		cmp = installedVer.Compare(targetVer)
+	case formatRPM:
+		cmp = rpmversion.NewVersion(installed).Compare(rpmversion.NewVersion(target))
+	default:
+		return false
+	}
+
+	switch operation {
+	case "less than":
+		return cmp < 0
+	case "less than or equal":
+		return cmp <= 0
+	case "equals":
+		return cmp == 0
+	case "greater than":
+		return cmp > 0
+	case "greater than or equal":
+		return cmp >= 0
+	case "not equal":
+		return cmp != 0
+	default:
+		return false
+	}
+}
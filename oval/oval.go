@@ -0,0 +1,316 @@
+// Package oval streams-parses OVAL (Open Vulnerability and Assessment
+// Language) v5.11 definitions, as published by Canonical, Red Hat, SUSE, and
+// the Azure Linux project, into an in-memory Index and matches them against a
+// host's installed packages. It is intentionally decoupled from the main
+// package's types: callers hand it a Package slice derived from whatever they
+// parsed, mirroring how the scan package takes its own minimal Package type.
+package oval
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Package is the minimal installed-package identity Match needs: name,
+// version, and the distro (lowercased os-release ID, e.g. "ubuntu", "sles",
+// "azurelinux") it was detected on.
+type Package struct {
+	Name    string
+	Version string
+	Distro  string
+}
+
+// Finding pairs an installed package with an OVAL definition whose criteria
+// its version satisfies.
+type Finding struct {
+	Package      string
+	DefinitionID string
+	CVEs         []string
+	Severity     string
+	FixedVersion string
+}
+
+// packageFormat identifies which vercmp semantics a check requires, derived
+// from whether the OVAL feed uses the rpminfo_* or dpkginfo_* element family.
+type packageFormat int
+
+const (
+	formatUnknown packageFormat = iota
+	formatRPM
+	formatDPKG
+)
+
+// check is a resolved rpminfo_test/dpkginfo_test: the package name and
+// version constraint it tests, plus the comparison semantics its format
+// requires.
+type check struct {
+	format    packageFormat
+	name      string
+	operation string
+	version   string
+}
+
+// criterionLeaf is a <criterion> reference to a resolved check.
+type criterionLeaf struct {
+	testID string
+	negate bool
+}
+
+// criteriaTree is a <criteria> node: an AND/OR of leaf criteria and nested
+// subtrees, mirroring the OVAL criteria grammar recursively.
+type criteriaTree struct {
+	operator string
+	negate   bool
+	leaves   []criterionLeaf
+	children []criteriaTree
+}
+
+// definition is a resolved OVAL <definition>: the CVEs and severity from its
+// metadata, plus the criteria tree that decides whether an installed package
+// is vulnerable.
+type definition struct {
+	id       string
+	cves     []string
+	severity string
+	criteria criteriaTree
+}
+
+// Index is an in-memory, queryable set of OVAL definitions, keyed by distro
+// and package name so Match only has to look at the definitions relevant to
+// an installed package rather than scanning the whole feed.
+type Index struct {
+	checks      map[string]check
+	definitions map[string][]*definition
+}
+
+// NewIndex creates an empty Index ready for Load.
+func NewIndex() *Index {
+	return &Index{
+		checks:      make(map[string]check),
+		definitions: make(map[string][]*definition),
+	}
+}
+
+// xmlObject is the shared shape of <rpminfo_object> and <dpkginfo_object>.
+type xmlObject struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name"`
+}
+
+// xmlEVR is an OVAL <evr> element: a version bound with the operation that
+// relates it to the installed version (e.g. "less than").
+type xmlEVR struct {
+	Operation string `xml:"operation,attr"`
+	Value     string `xml:",chardata"`
+}
+
+// xmlState is the shared shape of <rpminfo_state> and <dpkginfo_state>.
+type xmlState struct {
+	ID  string `xml:"id,attr"`
+	EVR xmlEVR `xml:"evr"`
+}
+
+// xmlObjectRef and xmlStateRef are the <object>/<state> children of a test,
+// kept as separate types since their reference attributes have different
+// names in the schema.
+type xmlObjectRef struct {
+	Ref string `xml:"object_ref,attr"`
+}
+
+type xmlStateRef struct {
+	Ref string `xml:"state_ref,attr"`
+}
+
+// xmlTest is the shared shape of <rpminfo_test> and <dpkginfo_test>.
+type xmlTest struct {
+	ID     string       `xml:"id,attr"`
+	Object xmlObjectRef `xml:"object"`
+	State  xmlStateRef  `xml:"state"`
+}
+
+// xmlReference is a <reference> child of <metadata>, usually a CVE.
+type xmlReference struct {
+	RefID  string `xml:"ref_id,attr"`
+	Source string `xml:"source,attr"`
+}
+
+// xmlMetadata is the <metadata> child of a <definition>.
+type xmlMetadata struct {
+	References []xmlReference `xml:"reference"`
+	Severity   string         `xml:"advisory>severity"`
+}
+
+// xmlCriterion is a <criterion> leaf of a <criteria> tree.
+type xmlCriterion struct {
+	TestRef string `xml:"test_ref,attr"`
+	Negate  bool   `xml:"negate,attr"`
+}
+
+// xmlCriteria is a <criteria> node. encoding/xml resolves the recursive
+// Criterias field natively, so a single DecodeElement walks the whole tree.
+type xmlCriteria struct {
+	Operator   string         `xml:"operator,attr"`
+	Negate     bool           `xml:"negate,attr"`
+	Criterions []xmlCriterion `xml:"criterion"`
+	Criterias  []xmlCriteria  `xml:"criteria"`
+}
+
+// xmlDefinition is a <definition> from the OVAL <definitions> section.
+type xmlDefinition struct {
+	ID       string      `xml:"id,attr"`
+	Metadata xmlMetadata `xml:"metadata"`
+	Criteria xmlCriteria `xml:"criteria"`
+}
+
+// pendingTest is an xmlTest whose object/state references haven't been
+// resolved yet, since OVAL lists <tests>, <objects>, and <states> as
+// siblings with no guaranteed ordering between them.
+type pendingTest struct {
+	id        string
+	format    packageFormat
+	objectRef string
+	stateRef  string
+}
+
+// Load streams OVAL v5.11 XML definitions for distro (the os-release ID they
+// apply to, e.g. "ubuntu", "sles", "azurelinux") from r and merges them into
+// idx. It walks the document with Decoder.Token, handing only one element's
+// subtree to DecodeElement at a time, so multi-hundred-MB OVAL feeds don't
+// have to be held in memory as a single tree.
+func (idx *Index) Load(r io.Reader, distro string) error {
+	distro = strings.ToLower(distro)
+	dec := xml.NewDecoder(r)
+
+	objects := make(map[string]string)
+	states := make(map[string]xmlEVR)
+	var pending []pendingTest
+	var defs []xmlDefinition
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("oval: reading token: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "rpminfo_object", "dpkginfo_object":
+			var obj xmlObject
+			if err := dec.DecodeElement(&obj, &start); err != nil {
+				return fmt.Errorf("oval: decoding %s: %w", start.Name.Local, err)
+			}
+			objects[obj.ID] = obj.Name
+		case "rpminfo_state", "dpkginfo_state":
+			var st xmlState
+			if err := dec.DecodeElement(&st, &start); err != nil {
+				return fmt.Errorf("oval: decoding %s: %w", start.Name.Local, err)
+			}
+			states[st.ID] = st.EVR
+		case "rpminfo_test", "dpkginfo_test":
+			var t xmlTest
+			if err := dec.DecodeElement(&t, &start); err != nil {
+				return fmt.Errorf("oval: decoding %s: %w", start.Name.Local, err)
+			}
+			format := formatRPM
+			if start.Name.Local == "dpkginfo_test" {
+				format = formatDPKG
+			}
+			pending = append(pending, pendingTest{id: t.ID, format: format, objectRef: t.Object.Ref, stateRef: t.State.Ref})
+		case "definition":
+			var d xmlDefinition
+			if err := dec.DecodeElement(&d, &start); err != nil {
+				return fmt.Errorf("oval: decoding definition: %w", err)
+			}
+			defs = append(defs, d)
+		}
+	}
+
+	checks := make(map[string]check, len(pending))
+	for _, t := range pending {
+		name, ok := objects[t.objectRef]
+		if !ok {
+			continue
+		}
+		evr, ok := states[t.stateRef]
+		if !ok {
+			continue
+		}
+		checks[t.id] = check{
+			format:    t.format,
+			name:      name,
+			operation: evr.Operation,
+			version:   strings.TrimSpace(evr.Value),
+		}
+	}
+
+	for _, d := range defs {
+		def := &definition{
+			id:       d.ID,
+			severity: d.Metadata.Severity,
+			criteria: toCriteriaTree(d.Criteria),
+		}
+		for _, ref := range d.Metadata.References {
+			if strings.EqualFold(ref.Source, "CVE") || strings.HasPrefix(strings.ToUpper(ref.RefID), "CVE-") {
+				def.cves = append(def.cves, ref.RefID)
+			}
+		}
+
+		for _, name := range referencedPackageNames(def.criteria, checks) {
+			key := distro + "/" + name
+			idx.definitions[key] = append(idx.definitions[key], def)
+		}
+	}
+
+	for id, c := range checks {
+		idx.checks[id] = c
+	}
+
+	return nil
+}
+
+// toCriteriaTree converts a decoded xmlCriteria subtree into the internal
+// criteriaTree shape Match walks.
+func toCriteriaTree(c xmlCriteria) criteriaTree {
+	tree := criteriaTree{operator: strings.ToUpper(c.Operator), negate: c.Negate}
+	for _, leaf := range c.Criterions {
+		tree.leaves = append(tree.leaves, criterionLeaf{testID: leaf.TestRef, negate: leaf.Negate})
+	}
+	for _, child := range c.Criterias {
+		tree.children = append(tree.children, toCriteriaTree(child))
+	}
+	return tree
+}
+
+// referencedPackageNames collects the distinct package names any leaf of
+// tree's checks refers to, used to index a definition by every package it
+// could possibly apply to, independent of how negate/AND/OR combine at match
+// time.
+func referencedPackageNames(tree criteriaTree, checks map[string]check) []string {
+	seen := make(map[string]struct{})
+	collectPackageNames(tree, checks, seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	return names
+}
+
+func collectPackageNames(tree criteriaTree, checks map[string]check, seen map[string]struct{}) {
+	for _, leaf := range tree.leaves {
+		if c, ok := checks[leaf.testID]; ok {
+			seen[c.name] = struct{}{}
+		}
+	}
+	for _, child := range tree.children {
+		collectPackageNames(child, checks, seen)
+	}
+}
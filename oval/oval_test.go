@@ -0,0 +1,334 @@
+package oval
+
+import (
+	"strings"
+	"testing"
+)
+
+// ubuntuFixture is a trimmed Canonical-style dpkginfo OVAL feed: one
+// definition, vulnerable when openssl is older than the fixed version.
+const ubuntuFixture = `<?xml version="1.0"?>
+<oval_definitions>
+  <definitions>
+    <definition id="oval:com.ubuntu.noble:def:123456780000000" class="vulnerability">
+      <metadata>
+        <title>CVE-2024-0001 on Ubuntu 24.04 LTS (noble) - openssl</title>
+        <reference ref_id="CVE-2024-0001" source="CVE"/>
+        <advisory><severity>High</severity></advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criterion test_ref="oval:com.ubuntu.noble:tst:1"/>
+      </criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <dpkginfo_test id="oval:com.ubuntu.noble:tst:1">
+      <object object_ref="oval:com.ubuntu.noble:obj:1"/>
+      <state state_ref="oval:com.ubuntu.noble:ste:1"/>
+    </dpkginfo_test>
+  </tests>
+  <objects>
+    <dpkginfo_object id="oval:com.ubuntu.noble:obj:1">
+      <name>openssl</name>
+    </dpkginfo_object>
+  </objects>
+  <states>
+    <dpkginfo_state id="oval:com.ubuntu.noble:ste:1">
+      <evr operation="less than">3.0.13-0ubuntu3.2</evr>
+    </dpkginfo_state>
+  </states>
+</oval_definitions>`
+
+// redhatFixture is a trimmed Red Hat-style rpminfo OVAL feed.
+const redhatFixture = `<?xml version="1.0"?>
+<oval_definitions>
+  <definitions>
+    <definition id="oval:com.redhat.rhsa:def:20240001" class="patch">
+      <metadata>
+        <title>RHSA-2024:0001: bash security update</title>
+        <reference ref_id="CVE-2024-0002" source="CVE"/>
+        <advisory><severity>Moderate</severity></advisory>
+      </metadata>
+      <criteria operator="OR">
+        <criterion test_ref="oval:com.redhat.rhsa:tst:1"/>
+      </criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <rpminfo_test id="oval:com.redhat.rhsa:tst:1">
+      <object object_ref="oval:com.redhat.rhsa:obj:1"/>
+      <state state_ref="oval:com.redhat.rhsa:ste:1"/>
+    </rpminfo_test>
+  </tests>
+  <objects>
+    <rpminfo_object id="oval:com.redhat.rhsa:obj:1">
+      <name>bash</name>
+    </rpminfo_object>
+  </objects>
+  <states>
+    <rpminfo_state id="oval:com.redhat.rhsa:ste:1">
+      <evr operation="less than">0:4.4.20-4.el8_9</evr>
+    </rpminfo_state>
+  </states>
+</oval_definitions>`
+
+// suseFixture is a trimmed SUSE CVRF-derived rpminfo OVAL feed.
+const suseFixture = `<?xml version="1.0"?>
+<oval_definitions>
+  <definitions>
+    <definition id="oval:org.opensuse.security:def:20240003" class="vulnerability">
+      <metadata>
+        <title>CVE-2024-0003 affects glibc</title>
+        <reference ref_id="CVE-2024-0003" source="CVE"/>
+        <advisory><severity>Important</severity></advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criterion test_ref="oval:org.opensuse.security:tst:1"/>
+      </criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <rpminfo_test id="oval:org.opensuse.security:tst:1">
+      <object object_ref="oval:org.opensuse.security:obj:1"/>
+      <state state_ref="oval:org.opensuse.security:ste:1"/>
+    </rpminfo_test>
+  </tests>
+  <objects>
+    <rpminfo_object id="oval:org.opensuse.security:obj:1">
+      <name>glibc</name>
+    </rpminfo_object>
+  </objects>
+  <states>
+    <rpminfo_state id="oval:org.opensuse.security:ste:1">
+      <evr operation="less than">2.38-10.150500.1</evr>
+    </rpminfo_state>
+  </states>
+</oval_definitions>`
+
+// azureLinuxFixture is a trimmed Azure Linux project rpminfo OVAL feed,
+// including a nested <criteria> subtree to exercise the recursive walk.
+const azureLinuxFixture = `<?xml version="1.0"?>
+<oval_definitions>
+  <definitions>
+    <definition id="oval:com.microsoft.azurelinux:def:20240004" class="vulnerability">
+      <metadata>
+        <title>CVE-2024-0004 affects curl</title>
+        <reference ref_id="CVE-2024-0004" source="CVE"/>
+        <advisory><severity>Critical</severity></advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criteria operator="OR">
+          <criterion test_ref="oval:com.microsoft.azurelinux:tst:1"/>
+        </criteria>
+      </criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <rpminfo_test id="oval:com.microsoft.azurelinux:tst:1">
+      <object object_ref="oval:com.microsoft.azurelinux:obj:1"/>
+      <state state_ref="oval:com.microsoft.azurelinux:ste:1"/>
+    </rpminfo_test>
+  </tests>
+  <objects>
+    <rpminfo_object id="oval:com.microsoft.azurelinux:obj:1">
+      <name>curl</name>
+    </rpminfo_object>
+  </objects>
+  <states>
+    <rpminfo_state id="oval:com.microsoft.azurelinux:ste:1">
+      <evr operation="less than">8.8.0-1.azl3</evr>
+    </rpminfo_state>
+  </states>
+</oval_definitions>`
+
+// ubuntuPlatformFixture models the shape real Canonical feeds actually use:
+// an AND of a release/platform criterion (here a dpkginfo_test against the
+// "base-files" package, standing in for the release marker) and an OR of the
+// package criteria. ubuntuFixture above omits the platform wrapper, which let
+// the AND/platform gap in evalNode ship unnoticed.
+const ubuntuPlatformFixture = `<?xml version="1.0"?>
+<oval_definitions>
+  <definitions>
+    <definition id="oval:com.ubuntu.noble:def:123456780000001" class="vulnerability">
+      <metadata>
+        <title>CVE-2024-0005 on Ubuntu 24.04 LTS (noble) - openssl</title>
+        <reference ref_id="CVE-2024-0005" source="CVE"/>
+        <advisory><severity>High</severity></advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criterion test_ref="oval:com.ubuntu.noble:tst:10" comment="noble is installed"/>
+        <criteria operator="OR">
+          <criterion test_ref="oval:com.ubuntu.noble:tst:11"/>
+        </criteria>
+      </criteria>
+    </definition>
+  </definitions>
+  <tests>
+    <dpkginfo_test id="oval:com.ubuntu.noble:tst:10">
+      <object object_ref="oval:com.ubuntu.noble:obj:10"/>
+      <state state_ref="oval:com.ubuntu.noble:ste:10"/>
+    </dpkginfo_test>
+    <dpkginfo_test id="oval:com.ubuntu.noble:tst:11">
+      <object object_ref="oval:com.ubuntu.noble:obj:11"/>
+      <state state_ref="oval:com.ubuntu.noble:ste:11"/>
+    </dpkginfo_test>
+  </tests>
+  <objects>
+    <dpkginfo_object id="oval:com.ubuntu.noble:obj:10">
+      <name>base-files</name>
+    </dpkginfo_object>
+    <dpkginfo_object id="oval:com.ubuntu.noble:obj:11">
+      <name>openssl</name>
+    </dpkginfo_object>
+  </objects>
+  <states>
+    <dpkginfo_state id="oval:com.ubuntu.noble:ste:10">
+      <evr operation="greater than or equal">13ubuntu10</evr>
+    </dpkginfo_state>
+    <dpkginfo_state id="oval:com.ubuntu.noble:ste:11">
+      <evr operation="less than">3.0.13-0ubuntu3.2</evr>
+    </dpkginfo_state>
+  </states>
+</oval_definitions>`
+
+func TestIndex_LoadAndMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		distro  string
+		pkg     Package
+		wantDef string
+		wantCVE string
+		wantFix string
+		wantHit bool
+	}{
+		{
+			name:    "Ubuntu vulnerable openssl",
+			fixture: ubuntuFixture,
+			distro:  "ubuntu",
+			pkg:     Package{Name: "openssl", Version: "3.0.13-0ubuntu3.1", Distro: "ubuntu"},
+			wantDef: "oval:com.ubuntu.noble:def:123456780000000",
+			wantCVE: "CVE-2024-0001",
+			wantFix: "3.0.13-0ubuntu3.2",
+			wantHit: true,
+		},
+		{
+			name:    "Ubuntu fixed openssl is not a match",
+			fixture: ubuntuFixture,
+			distro:  "ubuntu",
+			pkg:     Package{Name: "openssl", Version: "3.0.13-0ubuntu3.2", Distro: "ubuntu"},
+			wantHit: false,
+		},
+		{
+			name:    "RHEL vulnerable bash",
+			fixture: redhatFixture,
+			distro:  "rhel",
+			pkg:     Package{Name: "bash", Version: "4.4.20-3.el8", Distro: "rhel"},
+			wantDef: "oval:com.redhat.rhsa:def:20240001",
+			wantCVE: "CVE-2024-0002",
+			wantFix: "0:4.4.20-4.el8_9",
+			wantHit: true,
+		},
+		{
+			name:    "SUSE vulnerable glibc",
+			fixture: suseFixture,
+			distro:  "sles",
+			pkg:     Package{Name: "glibc", Version: "2.38-9.150500.1", Distro: "sles"},
+			wantDef: "oval:org.opensuse.security:def:20240003",
+			wantCVE: "CVE-2024-0003",
+			wantFix: "2.38-10.150500.1",
+			wantHit: true,
+		},
+		{
+			name:    "Ubuntu vulnerable openssl behind an AND(platform, OR(pkg)) wrapper",
+			fixture: ubuntuPlatformFixture,
+			distro:  "ubuntu",
+			pkg:     Package{Name: "openssl", Version: "3.0.13-0ubuntu3.1", Distro: "ubuntu"},
+			wantDef: "oval:com.ubuntu.noble:def:123456780000001",
+			wantCVE: "CVE-2024-0005",
+			wantFix: "3.0.13-0ubuntu3.2",
+			wantHit: true,
+		},
+		{
+			name:    "Ubuntu fixed openssl behind an AND(platform, OR(pkg)) wrapper is not a match",
+			fixture: ubuntuPlatformFixture,
+			distro:  "ubuntu",
+			pkg:     Package{Name: "openssl", Version: "3.0.13-0ubuntu3.2", Distro: "ubuntu"},
+			wantHit: false,
+		},
+		{
+			name:    "Azure Linux vulnerable curl via nested criteria",
+			fixture: azureLinuxFixture,
+			distro:  "azurelinux",
+			pkg:     Package{Name: "curl", Version: "8.7.1-1.azl3", Distro: "azurelinux"},
+			wantDef: "oval:com.microsoft.azurelinux:def:20240004",
+			wantCVE: "CVE-2024-0004",
+			wantFix: "8.8.0-1.azl3",
+			wantHit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := NewIndex()
+			if err := idx.Load(strings.NewReader(tt.fixture), tt.distro); err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+
+			findings := Match([]Package{tt.pkg}, idx)
+			if !tt.wantHit {
+				if len(findings) != 0 {
+					t.Fatalf("Match() = %+v, want no findings", findings)
+				}
+				return
+			}
+
+			if len(findings) != 1 {
+				t.Fatalf("Match() returned %d findings, want 1: %+v", len(findings), findings)
+			}
+			got := findings[0]
+			if got.DefinitionID != tt.wantDef {
+				t.Errorf("DefinitionID = %q, want %q", got.DefinitionID, tt.wantDef)
+			}
+			if len(got.CVEs) != 1 || got.CVEs[0] != tt.wantCVE {
+				t.Errorf("CVEs = %v, want [%q]", got.CVEs, tt.wantCVE)
+			}
+			if got.FixedVersion != tt.wantFix {
+				t.Errorf("FixedVersion = %q, want %q", got.FixedVersion, tt.wantFix)
+			}
+		})
+	}
+}
+
+func TestMatch_UnrelatedDistroNoMatch(t *testing.T) {
+	idx := NewIndex()
+	if err := idx.Load(strings.NewReader(ubuntuFixture), "ubuntu"); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	findings := Match([]Package{{Name: "openssl", Version: "3.0.0", Distro: "debian"}}, idx)
+	if len(findings) != 0 {
+		t.Errorf("Match() across distros = %+v, want no findings", findings)
+	}
+}
+
+func TestMatch_NegatedCriterionExcludesMatch(t *testing.T) {
+	idx := NewIndex()
+	// A single negated criterion flips the check: the definition only fires
+	// when the installed version is NOT less than the state's version.
+	fixture := strings.Replace(ubuntuFixture, `<criterion test_ref="oval:com.ubuntu.noble:tst:1"/>`,
+		`<criterion test_ref="oval:com.ubuntu.noble:tst:1" negate="true"/>`, 1)
+	if err := idx.Load(strings.NewReader(fixture), "ubuntu"); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	vulnerable := Package{Name: "openssl", Version: "3.0.13-0ubuntu3.1", Distro: "ubuntu"}
+	if findings := Match([]Package{vulnerable}, idx); len(findings) != 0 {
+		t.Errorf("Match() with negated criterion = %+v, want no findings for a version under the bound", findings)
+	}
+
+	fixed := Package{Name: "openssl", Version: "3.0.13-0ubuntu3.2", Distro: "ubuntu"}
+	if findings := Match([]Package{fixed}, idx); len(findings) != 1 {
+		t.Errorf("Match() with negated criterion = %+v, want one finding for a version at/above the bound", findings)
+	}
+}
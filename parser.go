@@ -18,48 +18,204 @@ type OSDistro int
 const (
 	// DistroUnknown indicates the OS distribution could not be determined.
 	DistroUnknown OSDistro = iota
-	// DistroUbuntu indicates Ubuntu/Debian distribution.
+	// DistroUbuntu indicates an Ubuntu distribution.
 	DistroUbuntu
 	// DistroAzureLinux indicates Microsoft Azure Linux distribution.
 	DistroAzureLinux
 	// DistroMariner indicates CBL-Mariner distribution.
 	DistroMariner
+	// DistroSUSE indicates SUSE/openSUSE distribution (SLES, SLED, openSUSE Leap, SUSE OpenStack Cloud).
+	DistroSUSE
+	// DistroDebian indicates a Debian distribution.
+	DistroDebian
+	// DistroRHEL indicates Red Hat Enterprise Linux.
+	DistroRHEL
+	// DistroCentOS indicates CentOS.
+	DistroCentOS
+	// DistroFedora indicates Fedora.
+	DistroFedora
+	// DistroRocky indicates Rocky Linux.
+	DistroRocky
+	// DistroAlma indicates AlmaLinux.
+	DistroAlma
+	// DistroAmazonLinux indicates Amazon Linux.
+	DistroAmazonLinux
+	// DistroOracleLinux indicates Oracle Linux.
+	DistroOracleLinux
+	// DistroAlpine indicates Alpine Linux.
+	DistroAlpine
+	// DistroGentoo indicates Gentoo.
+	DistroGentoo
+	// DistroArch indicates Arch Linux.
+	DistroArch
+	// DistroPhoton indicates VMware Photon OS.
+	DistroPhoton
 )
 
 // String returns a string representation of the OSDistro.
 func (d OSDistro) String() string {
 	switch d {
 	case DistroUbuntu:
-		return "Ubuntu/Debian"
+		return "Ubuntu"
 	case DistroAzureLinux:
 		return "Azure Linux"
 	case DistroMariner:
 		return "CBL-Mariner"
+	case DistroSUSE:
+		return "SUSE"
+	case DistroDebian:
+		return "Debian"
+	case DistroRHEL:
+		return "RHEL"
+	case DistroCentOS:
+		return "CentOS"
+	case DistroFedora:
+		return "Fedora"
+	case DistroRocky:
+		return "Rocky Linux"
+	case DistroAlma:
+		return "AlmaLinux"
+	case DistroAmazonLinux:
+		return "Amazon Linux"
+	case DistroOracleLinux:
+		return "Oracle Linux"
+	case DistroAlpine:
+		return "Alpine"
+	case DistroGentoo:
+		return "Gentoo"
+	case DistroArch:
+		return "Arch Linux"
+	case DistroPhoton:
+		return "Photon OS"
 	default:
 		return "Unknown"
 	}
 }
 
+// PackageFormat identifies the downstream package-management tooling an
+// OSDistro uses, so callers can pick the right version-comparison library or
+// advisory feed without switching on every individual distro.
+type PackageFormat int
+
+const (
+	// PackageFormatUnknown indicates the package format could not be determined.
+	PackageFormatUnknown PackageFormat = iota
+	// PackageFormatDpkg indicates Debian/Ubuntu's dpkg/apt.
+	PackageFormatDpkg
+	// PackageFormatRPM indicates RPM-based package management (rpm/dnf/yum/zypper).
+	PackageFormatRPM
+	// PackageFormatAPK indicates Alpine's apk.
+	PackageFormatAPK
+	// PackageFormatPacman indicates Arch's pacman.
+	PackageFormatPacman
+)
+
+// String returns a string representation of the PackageFormat.
+func (f PackageFormat) String() string {
+	switch f {
+	case PackageFormatDpkg:
+		return "dpkg"
+	case PackageFormatRPM:
+		return "rpm"
+	case PackageFormatAPK:
+		return "apk"
+	case PackageFormatPacman:
+		return "pacman"
+	default:
+		return "unknown"
+	}
+}
+
+// PackageFormat returns the package-management tooling d uses. Distros
+// without version-comparison support in this parser (e.g. Gentoo's Portage)
+// report PackageFormatUnknown.
+func (d OSDistro) PackageFormat() PackageFormat {
+	switch d {
+	case DistroUbuntu, DistroDebian:
+		return PackageFormatDpkg
+	case DistroAzureLinux, DistroMariner, DistroSUSE, DistroRHEL, DistroCentOS,
+		DistroFedora, DistroRocky, DistroAlma, DistroAmazonLinux, DistroOracleLinux, DistroPhoton:
+		return PackageFormatRPM
+	case DistroAlpine:
+		return PackageFormatAPK
+	case DistroArch:
+		return PackageFormatPacman
+	default:
+		return PackageFormatUnknown
+	}
+}
+
+// PackageType distinguishes a binary package from the source package it was
+// built from, mirroring Clair's FeatureType split so callers can tell which
+// kind of OSPackage they're looking at without inspecting Parent.
+type PackageType int
+
+const (
+	// Binary indicates an installed, runnable package parsed from the
+	// "=== Installed Packages" section.
+	Binary PackageType = iota
+	// Source indicates a deduplicated upstream source package, synthesized
+	// from the "=== Source Packages" section and only ever reachable via
+	// another package's Parent pointer.
+	Source
+)
+
+// String returns a string representation of the PackageType.
+func (t PackageType) String() string {
+	switch t {
+	case Source:
+		return "source"
+	default:
+		return "binary"
+	}
+}
 
 // OSPackage represents an installed software package with its name and version.
 type OSPackage struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
+
+	// Type distinguishes an installed binary package from a deduplicated
+	// source package reachable only via another package's Parent.
+	Type PackageType `json:"type"`
+
+	// SourceName and SourceVersion identify the upstream source package that
+	// produced this binary package, when the build log includes that mapping.
+	// Vulnerability advisories are frequently published against the source
+	// package name (e.g. "openssl") rather than every binary it produces
+	// (e.g. "libssl3"), so callers should fall back to these when a lookup
+	// against Name comes up empty.
+	SourceName    string `json:"source_name,omitempty"`
+	SourceVersion string `json:"source_version,omitempty"`
+
+	// Parent points at the deduplicated source OSPackage this binary package
+	// was built from, mirroring the Feature/Parent pattern other scanners use
+	// to group binary packages under their shared source. Nil when no source
+	// mapping was found. Excluded from JSON to avoid a self-referential cycle.
+	Parent *OSPackage `json:"-"`
 }
 
-// ContainerImage represents a pre-pulled container image as a raw string.
+// ContainerImage represents a pre-pulled container image reference, split
+// into the parts defined by the Docker/OCI distribution spec. Raw preserves
+// the reference exactly as it appeared in the build log.
 type ContainerImage struct {
-	Name string `json:"name"`
+	Raw        string `json:"raw"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
 }
 
 // OSRelease represents operating system release information.
 type OSRelease struct {
-	Name        string   `json:"name"`         // NAME field
-	VersionID   string   `json:"version_id"`   // VERSION_ID field
-	Version     string   `json:"version"`      // VERSION field
-	PrettyName  string   `json:"pretty_name"`  // PRETTY_NAME field
-	ID          string   `json:"id"`           // ID field
-	Distro      OSDistro `json:"distro"`       // Detected OS distribution
+	Name          string        `json:"name"`              // NAME field
+	VersionID     string        `json:"version_id"`        // VERSION_ID field
+	Version       string        `json:"version"`           // VERSION field
+	PrettyName    string        `json:"pretty_name"`       // PRETTY_NAME field
+	ID            string        `json:"id"`                // ID field
+	IDLike        string        `json:"id_like,omitempty"` // ID_LIKE field, used when ID doesn't match a known distro
+	Distro        OSDistro      `json:"distro"`            // Detected OS distribution
+	PackageFormat PackageFormat `json:"package_format"`    // Package management tooling Distro uses
 }
 
 // String returns a string representation of the OSPackage.
@@ -67,9 +223,32 @@ func (p OSPackage) String() string {
 	return fmt.Sprintf("%s-%s", p.Name, p.Version)
 }
 
-// String returns a string representation of the ContainerImage.
+// String reconstructs the canonical form of the image reference:
+// registry/repository[:tag][@digest].
 func (c ContainerImage) String() string {
-	return c.Name
+	ref := c.Registry + "/" + c.Repository
+	if c.Tag != "" {
+		ref += ":" + c.Tag
+	}
+	if c.Digest != "" {
+		ref += "@" + c.Digest
+	}
+	return ref
+}
+
+// Valid reports whether Repository conforms to the Docker/OCI distribution
+// spec grammar: one or more "/"-separated components, each matching
+// [a-z0-9]+(?:[._-][a-z0-9]+)*.
+func (c ContainerImage) Valid() bool {
+	if c.Repository == "" {
+		return false
+	}
+	for _, component := range strings.Split(c.Repository, "/") {
+		if !repositoryComponentRegex.MatchString(component) {
+			return false
+		}
+	}
+	return true
 }
 
 // String returns a string representation of the OSRelease.
@@ -77,38 +256,39 @@ func (r OSRelease) String() string {
 	return fmt.Sprintf("%s %s (%s)", r.PrettyName, r.Version, r.Distro)
 }
 
-// Parser handles package parsing with compiled regex patterns for performance.
+// Parser handles package parsing by dispatching each "Installed Packages"
+// line to whichever registered DistroHandler matches the detected distro.
 type Parser struct {
-	ubuntuRegex    *regexp.Regexp
-	rpmRegex       *regexp.Regexp
-	nameVersionRpm *regexp.Regexp
+	handlers []DistroHandler
 }
 
-// NewParser creates a new Parser with pre-compiled regex patterns.
+// NewParser creates a new Parser with the built-in dpkg, rpm, and apk
+// DistroHandlers registered.
 func NewParser() (*Parser, error) {
-	// Ubuntu/Debian format: package/source,now version arch [status]
-	ubuntuRegex, err := regexp.Compile(`^([^/]+)/.*,now\s+(\S+)\s+\S+\s+\[.*\]`)
+	dpkg, err := newDpkgHandler()
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile Ubuntu regex: %w", err)
+		return nil, err
 	}
-
-	// RPM format: package-version-release.dist.arch (supports both azl3 and cm2)
-	rpmRegex, err := regexp.Compile(`^(.+)-([^-]+\.(azl3|cm2)\.(x86_64|noarch))$`)
+	rpm, err := newRPMHandler()
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile RPM regex: %w", err)
+		return nil, err
 	}
-
-	// Name-version separator for RPM packages
-	nameVersionRpm, err := regexp.Compile(`^(.+)-([0-9].*)$`)
+	apk, err := newAPKHandler()
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile RPM name-version regex: %w", err)
+		return nil, err
 	}
+	return NewParserWithHandlers(dpkg, rpm, apk)
+}
 
-	return &Parser{
-		ubuntuRegex:    ubuntuRegex,
-		rpmRegex:       rpmRegex,
-		nameVersionRpm: nameVersionRpm,
-	}, nil
+// NewParserWithHandlers creates a new Parser that dispatches package lines to
+// handlers, in the order given. Use this to register a DistroHandler for a
+// package format the built-ins don't cover (e.g. Gentoo's Portage or Arch's
+// pacman), alongside or instead of the defaults NewParser registers.
+func NewParserWithHandlers(handlers ...DistroHandler) (*Parser, error) {
+	if len(handlers) == 0 {
+		return nil, errors.New("at least one DistroHandler is required")
+	}
+	return &Parser{handlers: handlers}, nil
 }
 
 // ErrNoPackageSection is returned when no package section is found in the content.
@@ -129,7 +309,7 @@ func (p *Parser) Parse(reader io.Reader) (*ParseResult, error) {
 	}
 
 	result := &ParseResult{
-		Packages:        make([]OSPackage, 0, 100), // Pre-allocate with reasonable capacity
+		Packages:        make([]OSPackage, 0, 100),     // Pre-allocate with reasonable capacity
 		ContainerImages: make([]ContainerImage, 0, 50), // Pre-allocate for container images
 	}
 
@@ -142,20 +322,76 @@ func (p *Parser) Parse(reader io.Reader) (*ParseResult, error) {
 	inPackageSection := false
 	inOSReleaseSection := false
 	inContainerImagesSection := false
+	inSourcePackagesSection := false
+	inCrictlImagesSection := false
 	osReleaseData := make(map[string]string)
-	ubuntuPackageCount := 0
-	rpmPackageCount := 0
+	var packageLines []string
+	sourceByBinary := make(map[string]sourcePackage)
+	legacyReleasePath := ""
+	var legacyReleaseLines []string
+	legacyReleaseBlocks := make(map[string][]string)
 
 	for scanner.Scan() {
 		line := scanner.Text() // Don't trim space here to preserve "  - " prefix
 		trimmedLine := strings.TrimSpace(line)
 
+		// "=== Legacy Release Begin: <path> ===" / "=== Legacy Release End ==="
+		// wrap the contents of a pre-os-release-era release file (e.g.
+		// /etc/redhat-release), used as a fallback when there's no proper
+		// "=== os-release ===" block. The path is dynamic, so these are
+		// matched by prefix/suffix rather than the exact-match switch below.
+		if path, ok := strings.CutPrefix(trimmedLine, "=== Legacy Release Begin: "); ok {
+			legacyReleasePath = strings.TrimSuffix(path, " ===")
+			legacyReleaseLines = nil
+			inPackageSection = false
+			inOSReleaseSection = false
+			inContainerImagesSection = false
+			inSourcePackagesSection = false
+			continue
+		}
+		if trimmedLine == "=== Legacy Release End ===" {
+			if legacyReleasePath != "" {
+				legacyReleaseBlocks[legacyReleasePath] = legacyReleaseLines
+				legacyReleasePath = ""
+			}
+			continue
+		}
+		if legacyReleasePath != "" {
+			legacyReleaseLines = append(legacyReleaseLines, line)
+			continue
+		}
+
+		// "Pulled: <ref>" lines are emitted by `ctr`/`crictl pull` directly in
+		// the build log, outside any "=== ... ===" section, so they're
+		// recognized by prefix regardless of the current section.
+		if ref, ok := strings.CutPrefix(trimmedLine, "Pulled: "); ok {
+			ref = strings.TrimSpace(ref)
+			if ref != "" {
+				result.ContainerImages = append(result.ContainerImages, p.parseImageReference(ref))
+			}
+			continue
+		}
+
+		// `crictl images` prints a header row before its tabular output; the
+		// path is dynamic after that, so it's matched by its fields rather
+		// than the exact-match switch below.
+		if isCrictlImagesHeader(strings.Fields(trimmedLine)) {
+			inCrictlImagesSection = true
+			inPackageSection = false
+			inOSReleaseSection = false
+			inContainerImagesSection = false
+			inSourcePackagesSection = false
+			continue
+		}
+
 		// Check for section markers using trimmed line
 		switch trimmedLine {
 		case "=== Installed Packages Begin":
 			inPackageSection = true
 			inOSReleaseSection = false
 			inContainerImagesSection = false
+			inSourcePackagesSection = false
+			inCrictlImagesSection = false
 			continue
 		case "=== Installed Packages End":
 			inPackageSection = false
@@ -164,35 +400,46 @@ func (p *Parser) Parse(reader io.Reader) (*ParseResult, error) {
 			inOSReleaseSection = true
 			inPackageSection = false
 			inContainerImagesSection = false
+			inSourcePackagesSection = false
+			inCrictlImagesSection = false
 			continue
 		case "=== os-release End":
 			inOSReleaseSection = false
 			continue
+		case "=== Source Packages Begin":
+			inSourcePackagesSection = true
+			inPackageSection = false
+			inOSReleaseSection = false
+			inContainerImagesSection = false
+			inCrictlImagesSection = false
+			continue
+		case "=== Source Packages End":
+			inSourcePackagesSection = false
+			continue
 		case "containerd images pre-pulled:":
 			inContainerImagesSection = true
 			inPackageSection = false
 			inOSReleaseSection = false
+			inSourcePackagesSection = false
+			inCrictlImagesSection = false
 			continue
 		case "", "Listing...":
-			if inPackageSection || inOSReleaseSection || inContainerImagesSection {
+			if inPackageSection || inOSReleaseSection || inContainerImagesSection || inSourcePackagesSection || inCrictlImagesSection {
 				continue
 			}
 		}
 
 		if inPackageSection {
-			pkg := p.parsePackageLine(trimmedLine)
-			if pkg != nil {
-				result.Packages = append(result.Packages, *pkg)
-				
-				// Count package types for format detection
-				if p.isUbuntuPackageFormat(trimmedLine) {
-					ubuntuPackageCount++
-				} else if p.isRPMPackageFormat(trimmedLine) {
-					rpmPackageCount++
-				}
-			}
+			// Buffered rather than parsed here: which DistroHandler applies
+			// isn't known until the os-release block (which in a real build
+			// log comes after this section) has been read in full.
+			packageLines = append(packageLines, trimmedLine)
 		} else if inOSReleaseSection {
 			p.parseOSReleaseLine(trimmedLine, osReleaseData)
+		} else if inSourcePackagesSection {
+			if binary, src, ok := p.parseSourcePackageLine(trimmedLine); ok {
+				sourceByBinary[binary] = src
+			}
 		} else if inContainerImagesSection {
 			// Check if this line is a container image entry (starts with "  - ")
 			if strings.HasPrefix(line, "  - ") {
@@ -204,6 +451,20 @@ func (p *Parser) Parse(reader io.Reader) (*ParseResult, error) {
 				// Non-empty line that doesn't start with "  - " means end of section
 				inContainerImagesSection = false
 			}
+		} else if inCrictlImagesSection {
+			// A data row has exactly the columns the header does: IMAGE, TAG,
+			// IMAGE ID, SIZE. Anything else (a blank separator line already
+			// handled above, or the next log line) ends the section.
+			fields := strings.Fields(trimmedLine)
+			if len(fields) != 4 {
+				inCrictlImagesSection = false
+				continue
+			}
+			raw := fields[0]
+			if tag := fields[1]; tag != "<none>" {
+				raw = fields[0] + ":" + tag
+			}
+			result.ContainerImages = append(result.ContainerImages, p.parseImageReference(raw))
 		}
 	}
 
@@ -211,35 +472,74 @@ func (p *Parser) Parse(reader io.Reader) (*ParseResult, error) {
 		return nil, fmt.Errorf("error reading content: %w", err)
 	}
 
-	// Determine distro based on package counts and OS release ID
+	// Determine distro based on the OS release ID/ID_LIKE fields and,
+	// failing that, whichever registered handler's format parses the most
+	// of the buffered package lines.
 	var detectedDistro OSDistro = DistroUnknown
-	
-	// First try to detect from OS release ID if available
+
+	// First try to detect from the ID field, falling back to each ID_LIKE
+	// token in order (e.g. ID=rocky ID_LIKE="rhel fedora") when ID itself
+	// isn't one of the distros this parser recognizes.
 	if len(osReleaseData) > 0 {
 		detectedDistro = p.detectDistroFromID(osReleaseData["ID"])
+		if detectedDistro == DistroUnknown {
+			detectedDistro = p.detectDistroFromIDLike(osReleaseData["ID_LIKE"])
+		}
+	}
+
+	// Dispatch the buffered package lines to whichever handler matches the
+	// detected distro. If OS release didn't provide a clear answer, fall
+	// back to whichever handler's format recognizes the most of them.
+	handler := p.handlerForDistro(detectedDistro)
+	if handler == nil {
+		handler, detectedDistro = p.detectHandlerFromLines(packageLines)
 	}
-	
-	// If OS release didn't provide clear answer, use package counts
-	if detectedDistro == DistroUnknown {
-		if rpmPackageCount > ubuntuPackageCount {
-			detectedDistro = DistroAzureLinux
-		} else if ubuntuPackageCount > rpmPackageCount {
-			detectedDistro = DistroUbuntu
+	if handler != nil {
+		for _, line := range packageLines {
+			if pkg := handler.ParsePackageLine(line); pkg != nil {
+				result.Packages = append(result.Packages, *pkg)
+			}
 		}
-		// If counts are equal, leave as Unknown - will be resolved by context
 	}
-	
+
+	// Attach source package info to the binary packages it was collected for,
+	// deduplicating the Parent pointer so every binary built from the same
+	// source shares one OSPackage instance.
+	if len(sourceByBinary) > 0 {
+		dedupedSources := make(map[string]*OSPackage, len(sourceByBinary))
+		for i := range result.Packages {
+			src, ok := sourceByBinary[result.Packages[i].Name]
+			if !ok {
+				continue
+			}
+			result.Packages[i].SourceName = src.name
+			result.Packages[i].SourceVersion = src.version
+
+			key := src.name + "@" + src.version
+			parent, ok := dedupedSources[key]
+			if !ok {
+				parent = &OSPackage{Name: src.name, Version: src.version, Type: Source}
+				dedupedSources[key] = parent
+			}
+			result.Packages[i].Parent = parent
+		}
+	}
+
 	// Build OS release info if we found any
 	if len(osReleaseData) > 0 {
-		
+
 		result.OSRelease = &OSRelease{
-			Name:       osReleaseData["NAME"],
-			VersionID:  osReleaseData["VERSION_ID"],
-			Version:    osReleaseData["VERSION"],
-			PrettyName: osReleaseData["PRETTY_NAME"],
-			ID:         osReleaseData["ID"],
-			Distro:     detectedDistro,
+			Name:          osReleaseData["NAME"],
+			VersionID:     osReleaseData["VERSION_ID"],
+			Version:       osReleaseData["VERSION"],
+			PrettyName:    osReleaseData["PRETTY_NAME"],
+			ID:            osReleaseData["ID"],
+			IDLike:        osReleaseData["ID_LIKE"],
+			Distro:        detectedDistro,
+			PackageFormat: detectedDistro.PackageFormat(),
 		}
+	} else if rel := p.legacyRelease(legacyReleaseBlocks); rel != nil {
+		result.OSRelease = rel
 	}
 
 	if len(result.Packages) == 0 && len(result.ContainerImages) == 0 && result.OSRelease == nil {
@@ -249,53 +549,55 @@ func (p *Parser) Parse(reader io.Reader) (*ParseResult, error) {
 	return result, nil
 }
 
+// nameVersionRegex splits an RPM NEVRA's "name-version" portion (the part
+// before the release/dist/arch suffix has already been trimmed off) into its
+// name and version. Shared by rpmHandler and parseSourcePackageLine, which
+// splits the same grammar out of a "%{SOURCERPM}" filename.
+var nameVersionRegex = regexp.MustCompile(`^(.+)-([0-9].*)$`)
+
+// sourcePackage is the upstream source package parsed from a
+// "=== Source Packages" entry, keyed by the binary package it built.
+type sourcePackage struct {
+	name    string
+	version string
+}
 
-// parsePackageLine parses a single package line and returns a OSPackage or nil.
-func (p *Parser) parsePackageLine(line string) *OSPackage {
-	if line == "" {
-		return nil
+// parseSourcePackageLine parses a single line from the "=== Source Packages"
+// section and returns the binary package name it maps from plus the
+// resolved source package. It accepts three shapes: dpkg-query's
+// "<binary> <source>" (no source version), dpkg-query's
+// "<binary> <source> <source-version>", and rpm's
+// "<binary> %{VERSION}-%{RELEASE} <sourcerpm>" (the output of
+// `rpm -qa --qf '%{NAME} %{VERSION}-%{RELEASE} %{SOURCERPM}\n'`) where
+// <sourcerpm> is a "name-version-release.src.rpm" NEVRA that must be split
+// back into name and version.
+func (p *Parser) parseSourcePackageLine(line string) (string, sourcePackage, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "", sourcePackage{}, false
 	}
 
-	// Try Ubuntu format first (more specific pattern)
-	if matches := p.ubuntuRegex.FindStringSubmatch(line); len(matches) >= 3 {
-		return &OSPackage{
-			Name:    matches[1],
-			Version: matches[2],
-		}
-	}
+	binary := fields[0]
+	last := fields[len(fields)-1]
 
-	// Try RPM format
-	if matches := p.rpmRegex.FindStringSubmatch(line); len(matches) >= 3 {
-		namePart := matches[1]
-		versionPart := matches[2]
-
-		// Extract the release version including .azl3/.cm2 but excluding architecture
-		// versionPart format: "3.azl3.x86_64" or "20.cm2.x86_64" -> we want "3.azl3" or "20.cm2"
-		releaseVersion := versionPart
-		if idx := strings.LastIndex(versionPart, "."); idx != -1 {
-			// Check if the last part is architecture (x86_64 or noarch)
-			arch := versionPart[idx+1:]
-			if arch == "x86_64" || arch == "noarch" {
-				releaseVersion = versionPart[:idx]
-			}
+	if strings.HasSuffix(last, ".src.rpm") {
+		nvr := strings.TrimSuffix(last, ".src.rpm")
+		idx := strings.LastIndex(nvr, "-")
+		if idx == -1 {
+			return binary, sourcePackage{name: nvr}, true
 		}
-
-		// Split name and version for RPM packages
-		if nameVersionMatches := p.nameVersionRpm.FindStringSubmatch(namePart); len(nameVersionMatches) >= 3 {
-			return &OSPackage{
-				Name:    nameVersionMatches[1],
-				Version: nameVersionMatches[2] + "-" + releaseVersion,
-			}
-		}
-
-		// Fallback for RPM packages where name-version split fails
-		return &OSPackage{
-			Name:    namePart,
-			Version: releaseVersion,
+		nameVersion, release := nvr[:idx], nvr[idx+1:]
+		if matches := nameVersionRegex.FindStringSubmatch(nameVersion); len(matches) >= 3 {
+			return binary, sourcePackage{name: matches[1], version: matches[2] + "-" + release}, true
 		}
+		return binary, sourcePackage{name: nameVersion, version: release}, true
 	}
 
-	return nil
+	src := sourcePackage{name: fields[1]}
+	if len(fields) >= 3 {
+		src.version = fields[2]
+	}
+	return binary, src, true
 }
 
 // parseOSReleaseLine parses a single OS release line and adds it to the data map.
@@ -303,71 +605,248 @@ func (p *Parser) parseOSReleaseLine(line string, data map[string]string) {
 	if line == "" {
 		return
 	}
-	
+
 	// OS release format: KEY="value" or KEY=value
 	if idx := strings.Index(line, "="); idx > 0 {
 		key := strings.TrimSpace(line[:idx])
 		value := strings.TrimSpace(line[idx+1:])
-		
+
 		// Remove surrounding quotes if present
 		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
 			value = value[1 : len(value)-1]
 		}
-		
+
 		// Only store the fields we're interested in
 		switch key {
-		case "NAME", "VERSION_ID", "VERSION", "PRETTY_NAME", "ID":
+		case "NAME", "VERSION_ID", "VERSION", "PRETTY_NAME", "ID", "ID_LIKE":
 			data[key] = value
 		}
 	}
 }
 
-// parseContainerImageLine parses a single container image line and returns a ContainerImage or nil.
+// parseContainerImageLine parses a single "  - <ref>" container image line
+// and returns a ContainerImage or nil.
 func (p *Parser) parseContainerImageLine(line string) *ContainerImage {
 	if line == "" {
 		return nil
 	}
-	
+
 	// Check if line starts with "  - " prefix
 	if !strings.HasPrefix(line, "  - ") {
 		return nil
 	}
-	
-	// Remove the "  - " prefix and keep the image name as-is
-	imageName := strings.TrimSpace(strings.TrimPrefix(line, "  - "))
-	if imageName == "" {
+
+	raw := strings.TrimSpace(strings.TrimPrefix(line, "  - "))
+	if raw == "" {
 		return nil
 	}
-	
-	return &ContainerImage{
-		Name: imageName,
+
+	image := p.parseImageReference(raw)
+	return &image
+}
+
+// repositoryComponentRegex matches a single "/"-delimited component of a
+// repository path, per the Docker/OCI distribution spec grammar.
+var repositoryComponentRegex = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// parseImageReference splits a container image reference into registry,
+// repository, tag, and digest following the Docker/OCI distribution spec.
+// The digest is split off on the last "@"; the tag on the last ":" that
+// falls after the final "/" (so a registry port, e.g. "localhost:5000", is
+// never mistaken for a tag). What's left of the first "/" is treated as the
+// registry only if it looks like a host - it contains "." or ":", or is
+// "localhost" - matching the rule Docker uses to tell "library/busybox" from
+// "myregistry.local/busybox". Anything else defaults to docker.io, with a
+// single-component repository implicitly namespaced under "library" the way
+// the Docker Hub does for official images.
+func (p *Parser) parseImageReference(raw string) ContainerImage {
+	img := ContainerImage{Raw: raw}
+	rest := raw
+
+	if at := strings.LastIndex(rest, "@"); at != -1 {
+		img.Digest = rest[at+1:]
+		rest = rest[:at]
+	}
+
+	lastSlash := strings.LastIndex(rest, "/")
+	if colon := strings.LastIndex(rest, ":"); colon != -1 && colon > lastSlash {
+		img.Tag = rest[colon+1:]
+		rest = rest[:colon]
+	}
+
+	if slash := strings.Index(rest, "/"); slash != -1 {
+		left := rest[:slash]
+		if strings.ContainsAny(left, ".:") || left == "localhost" {
+			img.Registry = left
+			img.Repository = rest[slash+1:]
+		}
 	}
+	if img.Registry == "" {
+		img.Registry = "docker.io"
+		img.Repository = rest
+		if !strings.Contains(img.Repository, "/") {
+			img.Repository = "library/" + img.Repository
+		}
+	}
+
+	return img
+}
+
+// isCrictlImagesHeader reports whether fields are the column headers of
+// `crictl images` tabular output: "IMAGE TAG IMAGE ID SIZE".
+func isCrictlImagesHeader(fields []string) bool {
+	return len(fields) == 5 &&
+		fields[0] == "IMAGE" && fields[1] == "TAG" &&
+		fields[2] == "IMAGE" && fields[3] == "ID" && fields[4] == "SIZE"
 }
 
-// isUbuntuPackageFormat checks if a line matches Ubuntu/Debian package format.
-func (p *Parser) isUbuntuPackageFormat(line string) bool {
-	return p.ubuntuRegex.MatchString(line)
+// distroIDTable maps an os-release ID (or ID_LIKE) token to the OSDistro it
+// identifies. Used both to resolve the ID field directly and, when that
+// doesn't match a known distro, to walk the ID_LIKE fallback chain.
+var distroIDTable = map[string]OSDistro{
+	"ubuntu":               DistroUbuntu,
+	"debian":               DistroDebian,
+	"azurelinux":           DistroAzureLinux,
+	"mariner":              DistroMariner,
+	"opensuse":             DistroSUSE,
+	"opensuse-leap":        DistroSUSE,
+	"sles":                 DistroSUSE,
+	"sled":                 DistroSUSE,
+	"suse-openstack-cloud": DistroSUSE,
+	"suse":                 DistroSUSE,
+	"rhel":                 DistroRHEL,
+	"centos":               DistroCentOS,
+	"fedora":               DistroFedora,
+	"rocky":                DistroRocky,
+	"almalinux":            DistroAlma,
+	"amzn":                 DistroAmazonLinux,
+	"amazon":               DistroAmazonLinux,
+	"ol":                   DistroOracleLinux,
+	"oracle":               DistroOracleLinux,
+	"alpine":               DistroAlpine,
+	"gentoo":               DistroGentoo,
+	"arch":                 DistroArch,
+	"photon":               DistroPhoton,
 }
 
-// isRPMPackageFormat checks if a line matches RPM package format.
-func (p *Parser) isRPMPackageFormat(line string) bool {
-	return p.rpmRegex.MatchString(line)
+// handlerForDistro returns the first registered DistroHandler whose format
+// matches distro, or nil if none do - e.g. distro is DistroUnknown, or it's
+// a distro (Gentoo's Portage) this parser has no built-in handler for.
+func (p *Parser) handlerForDistro(distro OSDistro) DistroHandler {
+	probe := &OSRelease{Distro: distro}
+	for _, h := range p.handlers {
+		if h.Matches(probe) {
+			return h
+		}
+	}
+	return nil
+}
+
+// detectHandlerFromLines picks whichever registered handler's
+// ParsePackageLine recognizes the most of lines, used as a fallback when the
+// os-release block is missing or its ID/ID_LIKE didn't match a known
+// distro. A tie - including every handler recognizing none of the lines -
+// leaves the distro unresolved rather than guessing.
+func (p *Parser) detectHandlerFromLines(lines []string) (DistroHandler, OSDistro) {
+	var best DistroHandler
+	bestCount := 0
+	tie := false
+
+	for _, h := range p.handlers {
+		count := 0
+		for _, line := range lines {
+			if h.ParsePackageLine(line) != nil {
+				count++
+			}
+		}
+		switch {
+		case count == 0:
+			continue
+		case best == nil || count > bestCount:
+			best, bestCount, tie = h, count, false
+		case count == bestCount:
+			tie = true
+		}
+	}
+
+	if best == nil || tie {
+		return nil, DistroUnknown
+	}
+	return best, best.ID()
 }
 
 // detectDistroFromID detects the OS distribution from the os-release ID field.
 func (p *Parser) detectDistroFromID(id string) OSDistro {
-	switch id {
-	case "ubuntu", "debian":
-		return DistroUbuntu
-	case "azurelinux":
-		return DistroAzureLinux
-	case "mariner":
-		return DistroMariner
-	default:
-		return DistroUnknown
+	if distro, ok := distroIDTable[id]; ok {
+		return distro
+	}
+	return DistroUnknown
+}
+
+// detectDistroFromIDLike walks the space-separated, ordered ID_LIKE field
+// (e.g. "rhel fedora") and returns the first token that maps to a known
+// distro, for distros whose own ID this parser doesn't recognize directly.
+func (p *Parser) detectDistroFromIDLike(idLike string) OSDistro {
+	for _, token := range strings.Fields(idLike) {
+		if distro, ok := distroIDTable[token]; ok {
+			return distro
+		}
 	}
+	return DistroUnknown
 }
 
+// legacyReleaseFiles maps the pre-os-release-era release files this parser
+// accepts as a fallback to the distro each identifies, in the priority order
+// they're checked when more than one is present.
+var legacyReleaseFiles = []struct {
+	path   string
+	distro OSDistro
+}{
+	{"/etc/redhat-release", DistroRHEL},
+	{"/etc/centos-release", DistroCentOS},
+	{"/etc/SuSE-release", DistroSUSE},
+	{"/etc/alpine-release", DistroAlpine},
+	{"/etc/debian_version", DistroDebian},
+}
+
+// legacyReleaseVersionRegex extracts the version from release strings like
+// "CentOS Linux release 7.9.2009 (Core)" or "Red Hat Enterprise Linux Server
+// release 7.9 (Maipo)".
+var legacyReleaseVersionRegex = regexp.MustCompile(`release\s+([\d.]+)`)
+
+// legacyRelease builds an OSRelease from whichever legacy release file block
+// was captured, in legacyReleaseFiles priority order, for use when the build
+// log has no proper "=== os-release ===" section.
+func (p *Parser) legacyRelease(blocks map[string][]string) *OSRelease {
+	for _, f := range legacyReleaseFiles {
+		lines, ok := blocks[f.path]
+		if !ok || len(lines) == 0 {
+			continue
+		}
+		first := strings.TrimSpace(lines[0])
+		if first == "" {
+			continue
+		}
+
+		rel := &OSRelease{
+			Distro:        f.distro,
+			PackageFormat: f.distro.PackageFormat(),
+			Name:          first,
+			PrettyName:    first,
+		}
+		if m := legacyReleaseVersionRegex.FindStringSubmatch(first); len(m) == 2 {
+			rel.VersionID = m[1]
+			rel.Version = m[1]
+		} else {
+			// /etc/alpine-release and /etc/debian_version are a bare
+			// version string with no surrounding "release X.Y" text.
+			rel.VersionID = first
+			rel.Version = first
+		}
+		return rel
+	}
+	return nil
+}
 
 // ParseFromFile reads and parses VHD build output from a file.
 func (p *Parser) ParseFromFile(filename string) (*ParseResult, error) {
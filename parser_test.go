@@ -14,11 +14,28 @@ func TestNewParser(t *testing.T) {
 	if parser == nil {
 		t.Fatal("NewParser() returned nil parser")
 	}
-	if parser.ubuntuRegex == nil || parser.rpmRegex == nil || parser.nameVersionRpm == nil {
-		t.Fatal("NewParser() did not initialize all regex patterns")
+	if len(parser.handlers) != 3 {
+		t.Fatalf("NewParser() registered %d handlers, want 3", len(parser.handlers))
 	}
 }
 
+func TestNewParserWithHandlers(t *testing.T) {
+	if _, err := NewParserWithHandlers(); err == nil {
+		t.Fatal("NewParserWithHandlers() with no handlers should fail")
+	}
+
+	dpkg, err := newDpkgHandler()
+	if err != nil {
+		t.Fatalf("newDpkgHandler() failed: %v", err)
+	}
+	parser, err := NewParserWithHandlers(dpkg)
+	if err != nil {
+		t.Fatalf("NewParserWithHandlers() failed: %v", err)
+	}
+	if len(parser.handlers) != 1 {
+		t.Fatalf("NewParserWithHandlers() registered %d handlers, want 1", len(parser.handlers))
+	}
+}
 
 func TestOSPackage_String(t *testing.T) {
 	pkg := OSPackage{
@@ -52,9 +69,22 @@ func TestOSDistro_String(t *testing.T) {
 		expected string
 	}{
 		{DistroUnknown, "Unknown"},
-		{DistroUbuntu, "Ubuntu/Debian"},
+		{DistroUbuntu, "Ubuntu"},
 		{DistroAzureLinux, "Azure Linux"},
 		{DistroMariner, "CBL-Mariner"},
+		{DistroSUSE, "SUSE"},
+		{DistroDebian, "Debian"},
+		{DistroRHEL, "RHEL"},
+		{DistroCentOS, "CentOS"},
+		{DistroFedora, "Fedora"},
+		{DistroRocky, "Rocky Linux"},
+		{DistroAlma, "AlmaLinux"},
+		{DistroAmazonLinux, "Amazon Linux"},
+		{DistroOracleLinux, "Oracle Linux"},
+		{DistroAlpine, "Alpine"},
+		{DistroGentoo, "Gentoo"},
+		{DistroArch, "Arch Linux"},
+		{DistroPhoton, "Photon OS"},
 	}
 
 	for _, tt := range tests {
@@ -66,13 +96,238 @@ func TestOSDistro_String(t *testing.T) {
 	}
 }
 
+func TestOSDistro_PackageFormat(t *testing.T) {
+	tests := []struct {
+		distro   OSDistro
+		expected PackageFormat
+	}{
+		{DistroUbuntu, PackageFormatDpkg},
+		{DistroDebian, PackageFormatDpkg},
+		{DistroAzureLinux, PackageFormatRPM},
+		{DistroMariner, PackageFormatRPM},
+		{DistroSUSE, PackageFormatRPM},
+		{DistroRHEL, PackageFormatRPM},
+		{DistroCentOS, PackageFormatRPM},
+		{DistroFedora, PackageFormatRPM},
+		{DistroRocky, PackageFormatRPM},
+		{DistroAlma, PackageFormatRPM},
+		{DistroAmazonLinux, PackageFormatRPM},
+		{DistroOracleLinux, PackageFormatRPM},
+		{DistroPhoton, PackageFormatRPM},
+		{DistroAlpine, PackageFormatAPK},
+		{DistroArch, PackageFormatPacman},
+		{DistroGentoo, PackageFormatUnknown},
+		{DistroUnknown, PackageFormatUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.distro.String(), func(t *testing.T) {
+			if got := tt.distro.PackageFormat(); got != tt.expected {
+				t.Errorf("%s.PackageFormat() = %v, want %v", tt.distro, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPackageFormat_String(t *testing.T) {
+	tests := []struct {
+		format   PackageFormat
+		expected string
+	}{
+		{PackageFormatUnknown, "unknown"},
+		{PackageFormatDpkg, "dpkg"},
+		{PackageFormatRPM, "rpm"},
+		{PackageFormatAPK, "apk"},
+		{PackageFormatPacman, "pacman"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := tt.format.String(); got != tt.expected {
+				t.Errorf("PackageFormat.String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParser_detectDistroFromIDLike(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		idLike   string
+		expected OSDistro
+	}{
+		{"first recognized token wins", "centos rhel fedora", DistroCentOS},
+		{"skips unrecognized tokens", "bespoke-distro rhel", DistroRHEL},
+		{"no recognized tokens", "bespoke-distro", DistroUnknown},
+		{"empty", "", DistroUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.detectDistroFromIDLike(tt.idLike); got != tt.expected {
+				t.Errorf("detectDistroFromIDLike(%q) = %v, want %v", tt.idLike, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_IDLikeFallback(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	content := `=== os-release Begin
+NAME="Bespoke Linux"
+ID=bespokelinux
+ID_LIKE="rhel fedora"
+VERSION_ID="1.0"
+=== os-release End`
+
+	result, err := parser.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+	if result.OSRelease == nil {
+		t.Fatal("OSRelease = nil, want non-nil")
+	}
+	if result.OSRelease.Distro != DistroRHEL {
+		t.Errorf("Distro = %v, want %v", result.OSRelease.Distro, DistroRHEL)
+	}
+	if result.OSRelease.PackageFormat != PackageFormatRPM {
+		t.Errorf("PackageFormat = %v, want %v", result.OSRelease.PackageFormat, PackageFormatRPM)
+	}
+	if result.OSRelease.IDLike != "rhel fedora" {
+		t.Errorf("IDLike = %q, want %q", result.OSRelease.IDLike, "rhel fedora")
+	}
+}
+
+func TestParser_Parse_LegacyRelease(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		content         string
+		expectedDistro  OSDistro
+		expectedVersion string
+	}{
+		{
+			name: "redhat-release",
+			content: "=== Legacy Release Begin: /etc/redhat-release ===\n" +
+				"Red Hat Enterprise Linux Server release 7.9 (Maipo)\n" +
+				"=== Legacy Release End ===",
+			expectedDistro:  DistroRHEL,
+			expectedVersion: "7.9",
+		},
+		{
+			name: "centos-release",
+			content: "=== Legacy Release Begin: /etc/centos-release ===\n" +
+				"CentOS Linux release 7.9.2009 (Core)\n" +
+				"=== Legacy Release End ===",
+			expectedDistro:  DistroCentOS,
+			expectedVersion: "7.9.2009",
+		},
+		{
+			name: "debian_version",
+			content: "=== Legacy Release Begin: /etc/debian_version ===\n" +
+				"12.4\n" +
+				"=== Legacy Release End ===",
+			expectedDistro:  DistroDebian,
+			expectedVersion: "12.4",
+		},
+		{
+			name: "alpine-release",
+			content: "=== Legacy Release Begin: /etc/alpine-release ===\n" +
+				"3.19.1\n" +
+				"=== Legacy Release End ===",
+			expectedDistro:  DistroAlpine,
+			expectedVersion: "3.19.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() unexpected error = %v", err)
+			}
+			if result.OSRelease == nil {
+				t.Fatal("OSRelease = nil, want non-nil")
+			}
+			if result.OSRelease.Distro != tt.expectedDistro {
+				t.Errorf("Distro = %v, want %v", result.OSRelease.Distro, tt.expectedDistro)
+			}
+			if result.OSRelease.VersionID != tt.expectedVersion {
+				t.Errorf("VersionID = %q, want %q", result.OSRelease.VersionID, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+// TestParser_Parse_OSReleasePreferredOverLegacy confirms a proper
+// "=== os-release ===" block wins when a legacy release block is also
+// present, since os-release is the more authoritative source.
+func TestParser_Parse_OSReleasePreferredOverLegacy(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	content := `=== os-release Begin
+NAME="Ubuntu"
+ID=ubuntu
+VERSION_ID="24.04"
+=== os-release End
+=== Legacy Release Begin: /etc/debian_version ===
+12.4
+=== Legacy Release End ===`
+
+	result, err := parser.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+	if result.OSRelease == nil || result.OSRelease.Distro != DistroUbuntu {
+		t.Fatalf("OSRelease = %+v, want Distro %v", result.OSRelease, DistroUbuntu)
+	}
+}
+
 func TestContainerImage_String(t *testing.T) {
-	image := ContainerImage{
-		Name: "mcr.microsoft.com/oss/kubernetes/pause:3.6",
+	tests := []struct {
+		name     string
+		image    ContainerImage
+		expected string
+	}{
+		{
+			name:     "registry, repository, and tag",
+			image:    ContainerImage{Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause", Tag: "3.6"},
+			expected: "mcr.microsoft.com/oss/kubernetes/pause:3.6",
+		},
+		{
+			name:     "tag and digest",
+			image:    ContainerImage{Registry: "docker.io", Repository: "library/redis", Tag: "7", Digest: "sha256:deadbeef"},
+			expected: "docker.io/library/redis:7@sha256:deadbeef",
+		},
+		{
+			name:     "digest only",
+			image:    ContainerImage{Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause", Digest: "sha256:deadbeef"},
+			expected: "mcr.microsoft.com/oss/kubernetes/pause@sha256:deadbeef",
+		},
 	}
-	expected := "mcr.microsoft.com/oss/kubernetes/pause:3.6"
-	if got := image.String(); got != expected {
-		t.Errorf("ContainerImage.String() = %q, want %q", got, expected)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.image.String(); got != tt.expected {
+				t.Errorf("ContainerImage.String() = %q, want %q", got, tt.expected)
+			}
+		})
 	}
 }
 
@@ -91,28 +346,39 @@ func TestParser_parseContainerImageLine(t *testing.T) {
 			name:  "Microsoft container image with tag",
 			input: "  - mcr.microsoft.com/oss/kubernetes/pause:3.6",
 			expected: &ContainerImage{
-				Name: "mcr.microsoft.com/oss/kubernetes/pause:3.6",
+				Raw:        "mcr.microsoft.com/oss/kubernetes/pause:3.6",
+				Registry:   "mcr.microsoft.com",
+				Repository: "oss/kubernetes/pause",
+				Tag:        "3.6",
 			},
 		},
 		{
 			name:  "Azure CNI container image",
 			input: "  - mcr.microsoft.com/containernetworking/azure-cni:v1.4.59",
 			expected: &ContainerImage{
-				Name: "mcr.microsoft.com/containernetworking/azure-cni:v1.4.59",
+				Raw:        "mcr.microsoft.com/containernetworking/azure-cni:v1.4.59",
+				Registry:   "mcr.microsoft.com",
+				Repository: "containernetworking/azure-cni",
+				Tag:        "v1.4.59",
 			},
 		},
 		{
 			name:  "Complex nested repository path",
 			input: "  - mcr.microsoft.com/oss/v2/kubernetes/autoscaler/addon-resizer:v1.8.23-2",
 			expected: &ContainerImage{
-				Name: "mcr.microsoft.com/oss/v2/kubernetes/autoscaler/addon-resizer:v1.8.23-2",
+				Raw:        "mcr.microsoft.com/oss/v2/kubernetes/autoscaler/addon-resizer:v1.8.23-2",
+				Registry:   "mcr.microsoft.com",
+				Repository: "oss/v2/kubernetes/autoscaler/addon-resizer",
+				Tag:        "v1.8.23-2",
 			},
 		},
 		{
 			name:  "Image without tag",
 			input: "  - mcr.microsoft.com/oss/kubernetes/pause",
 			expected: &ContainerImage{
-				Name: "mcr.microsoft.com/oss/kubernetes/pause",
+				Raw:        "mcr.microsoft.com/oss/kubernetes/pause",
+				Registry:   "mcr.microsoft.com",
+				Repository: "oss/kubernetes/pause",
 			},
 		},
 		{
@@ -142,7 +408,92 @@ func TestParser_parseContainerImageLine(t *testing.T) {
 	}
 }
 
-func TestParser_parsePackageLine(t *testing.T) {
+func TestParser_parseImageReference(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected ContainerImage
+	}{
+		{
+			name:  "digest only",
+			input: "mcr.microsoft.com/oss/kubernetes/pause@sha256:0123456789012345678901234567890123456789012345678901234567890a",
+			expected: ContainerImage{
+				Raw:        "mcr.microsoft.com/oss/kubernetes/pause@sha256:0123456789012345678901234567890123456789012345678901234567890a",
+				Registry:   "mcr.microsoft.com",
+				Repository: "oss/kubernetes/pause",
+				Digest:     "sha256:0123456789012345678901234567890123456789012345678901234567890a",
+			},
+		},
+		{
+			name:  "tag and digest, as pinned in a Kubernetes manifest",
+			input: "mcr.microsoft.com/oss/kubernetes/pause:3.6@sha256:0123456789012345678901234567890123456789012345678901234567890a",
+			expected: ContainerImage{
+				Raw:        "mcr.microsoft.com/oss/kubernetes/pause:3.6@sha256:0123456789012345678901234567890123456789012345678901234567890a",
+				Registry:   "mcr.microsoft.com",
+				Repository: "oss/kubernetes/pause",
+				Tag:        "3.6",
+				Digest:     "sha256:0123456789012345678901234567890123456789012345678901234567890a",
+			},
+		},
+		{
+			name:  "implicit docker.io/library registry",
+			input: "redis:7",
+			expected: ContainerImage{
+				Raw:        "redis:7",
+				Registry:   "docker.io",
+				Repository: "library/redis",
+				Tag:        "7",
+			},
+		},
+		{
+			name:  "implicit docker.io registry, namespaced repository",
+			input: "someuser/myimage:latest",
+			expected: ContainerImage{
+				Raw:        "someuser/myimage:latest",
+				Registry:   "docker.io",
+				Repository: "someuser/myimage",
+				Tag:        "latest",
+			},
+		},
+		{
+			name:  "port in registry is not mistaken for a tag",
+			input: "localhost:5000/myimage:latest",
+			expected: ContainerImage{
+				Raw:        "localhost:5000/myimage:latest",
+				Registry:   "localhost:5000",
+				Repository: "myimage",
+				Tag:        "latest",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.parseImageReference(tt.input); got != tt.expected {
+				t.Errorf("parseImageReference(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// dispatchPackageLine tries line against every handler in turn and returns
+// the first match, the way Parser dispatches once a DistroHandler has been
+// selected for the whole buffered section.
+func dispatchPackageLine(handlers []DistroHandler, line string) *OSPackage {
+	for _, h := range handlers {
+		if pkg := h.ParsePackageLine(line); pkg != nil {
+			return pkg
+		}
+	}
+	return nil
+}
+
+func TestDistroHandlers_ParsePackageLine(t *testing.T) {
 	parser, err := NewParser()
 	if err != nil {
 		t.Fatalf("NewParser() failed: %v", err)
@@ -241,6 +592,78 @@ func TestParser_parsePackageLine(t *testing.T) {
 				Version: "24.3.1-1.cm2",
 			},
 		},
+		{
+			name:  "SUSE package format with openSUSE Leap dist tag",
+			input: "glibc-2.38-10.suse.lp154.x86_64",
+			expected: &OSPackage{
+				Name:    "glibc",
+				Version: "2.38-10.suse.lp154",
+			},
+		},
+		{
+			name:  "SUSE package format with bare numeric dist tag",
+			input: "glibc-2.38-10.150500.x86_64",
+			expected: &OSPackage{
+				Name:    "glibc",
+				Version: "2.38-10.150500",
+			},
+		},
+		{
+			name:  "RHEL package format",
+			input: "bash-5.1.8-6.el9.x86_64",
+			expected: &OSPackage{
+				Name:    "bash",
+				Version: "5.1.8-6.el9",
+			},
+		},
+		{
+			name:  "CentOS/Rocky/Alma package format",
+			input: "openssl-1.1.1k-7.el8.x86_64",
+			expected: &OSPackage{
+				Name:    "openssl",
+				Version: "1.1.1k-7.el8",
+			},
+		},
+		{
+			name:  "RPM package on aarch64",
+			input: "bash-5.1.8-6.el9.aarch64",
+			expected: &OSPackage{
+				Name:    "bash",
+				Version: "5.1.8-6.el9",
+			},
+		},
+		{
+			name:  "Fedora package format",
+			input: "glibc-2.38-10.fc39.x86_64",
+			expected: &OSPackage{
+				Name:    "glibc",
+				Version: "2.38-10.fc39",
+			},
+		},
+		{
+			name:  "Oracle Linux package format",
+			input: "glibc-2.38-10.ol9.x86_64",
+			expected: &OSPackage{
+				Name:    "glibc",
+				Version: "2.38-10.ol9",
+			},
+		},
+		{
+			name:  "Alpine apk package format",
+			input: "musl-1.2.4-r2",
+			expected: &OSPackage{
+				Name:    "musl",
+				Version: "1.2.4-r2",
+			},
+		},
+		{
+			name:  "Alpine apk package with digit in name",
+			input: "python3-3.11.6-r0",
+			expected: &OSPackage{
+				Name:    "python3",
+				Version: "3.11.6-r0",
+			},
+		},
 		{
 			name:     "Empty line",
 			input:    "",
@@ -260,9 +683,9 @@ func TestParser_parsePackageLine(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parser.parsePackageLine(tt.input)
+			result := dispatchPackageLine(parser.handlers, tt.input)
 			if !packageEqual(result, tt.expected) {
-				t.Errorf("parsePackageLine(%q) = %+v, want %+v", tt.input, result, tt.expected)
+				t.Errorf("ParsePackageLine(%q) = %+v, want %+v", tt.input, result, tt.expected)
 			}
 		})
 	}
@@ -325,11 +748,11 @@ func TestParser_parseOSReleaseLine(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			data := make(map[string]string)
 			parser.parseOSReleaseLine(tt.input, data)
-			
+
 			if len(data) != len(tt.expected) {
 				t.Errorf("parseOSReleaseLine() got %d items, want %d", len(data), len(tt.expected))
 			}
-			
+
 			for key, expectedValue := range tt.expected {
 				if actualValue, exists := data[key]; !exists {
 					t.Errorf("parseOSReleaseLine() missing key %q", key)
@@ -348,18 +771,18 @@ func TestParser_Parse(t *testing.T) {
 	}
 
 	tests := []struct {
-		name                     string
-		content                  string
-		expectedCount            int
-		expectedContainerImages  int
-		expectedError            error
-		expectedOSRelease        *OSRelease
+		name                    string
+		content                 string
+		expectedCount           int
+		expectedContainerImages int
+		expectedError           error
+		expectedOSRelease       *OSRelease
 	}{
 		{
 			name: "Full VHD build output with OS release and container images",
 			content: `Starting build
 === Installed Packages Begin
-adduser/noble,now 3.137ubuntu1 all [installed,automatic]
+glibc-2.38-10.azl3.x86_64
 filesystem-1.1-21.azl3.x86_64
 === Installed Packages End
 containerd images pre-pulled:
@@ -405,6 +828,17 @@ Successfully copied coredns binary`,
 			expectedContainerImages: 2,
 			expectedOSRelease:       nil,
 		},
+		{
+			name: "Pulled: lines and crictl images table, no packages",
+			content: `Pulled: mcr.microsoft.com/oss/kubernetes/pause:3.6
+IMAGE                                    TAG                 IMAGE ID            SIZE
+mcr.microsoft.com/containernetworking/azure-cni   v1.4.59             abcdef012345        10MB
+mcr.microsoft.com/oss/kubernetes/coredns          <none>              abcdef012346        20MB
+Disk usage:`,
+			expectedCount:           0,
+			expectedContainerImages: 3,
+			expectedOSRelease:       nil,
+		},
 		{
 			name: "Only OS release, no packages",
 			content: `=== os-release Begin
@@ -425,6 +859,26 @@ PRETTY_NAME="Ubuntu 24.04 LTS"
 				Distro:     DistroUbuntu,
 			},
 		},
+		{
+			name: "Only OS release, SLES distro",
+			content: `=== os-release Begin
+NAME="SLES"
+VERSION="15-SP5"
+ID=sles
+VERSION_ID="15.5"
+PRETTY_NAME="SUSE Linux Enterprise Server 15 SP5"
+=== os-release End`,
+			expectedCount:           0,
+			expectedContainerImages: 0,
+			expectedOSRelease: &OSRelease{
+				Name:       "SLES",
+				VersionID:  "15.5",
+				Version:    "15-SP5",
+				PrettyName: "SUSE Linux Enterprise Server 15 SP5",
+				ID:         "sles",
+				Distro:     DistroSUSE,
+			},
+		},
 		{
 			name: "No sections",
 			content: `Starting build
@@ -439,27 +893,27 @@ Disk usage:`,
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := parser.Parse(strings.NewReader(tt.content))
-			
+
 			if tt.expectedError != nil {
 				if !errors.Is(err, tt.expectedError) {
 					t.Errorf("Parse() error = %v, want %v", err, tt.expectedError)
 				}
 				return
 			}
-			
+
 			if err != nil {
 				t.Errorf("Parse() unexpected error = %v", err)
 				return
 			}
-			
+
 			if len(result.Packages) != tt.expectedCount {
 				t.Errorf("Parse() count = %d, want %d", len(result.Packages), tt.expectedCount)
 			}
-			
+
 			if len(result.ContainerImages) != tt.expectedContainerImages {
 				t.Errorf("Parse() container images count = %d, want %d", len(result.ContainerImages), tt.expectedContainerImages)
 			}
-			
+
 			if tt.expectedOSRelease == nil {
 				if result.OSRelease != nil {
 					t.Errorf("Parse() got OS release %+v, want nil", result.OSRelease)
@@ -482,9 +936,6 @@ Disk usage:`,
 	}
 }
 
-
-
-
 func TestParser_ParseFromFile_ErrorCases(t *testing.T) {
 	parser, err := NewParser()
 	if err != nil {
@@ -537,7 +988,7 @@ func containerImageEqual(a, b *ContainerImage) bool {
 	if a == nil || b == nil {
 		return false
 	}
-	return a.Name == b.Name
+	return *a == *b
 }
 
 // Benchmark tests for performance comparison
@@ -547,7 +998,7 @@ func BenchmarkParser_parsePackageLine_Ubuntu(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		parser.parsePackageLine(line)
+		dispatchPackageLine(parser.handlers, line)
 	}
 }
 
@@ -557,7 +1008,7 @@ func BenchmarkParser_parsePackageLine_RPM(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		parser.parsePackageLine(line)
+		dispatchPackageLine(parser.handlers, line)
 	}
 }
 
@@ -582,3 +1033,118 @@ PRETTY_NAME="Microsoft Azure Linux 3.0"
 		parser.Parse(strings.NewReader(content))
 	}
 }
+
+func TestPackageType_String(t *testing.T) {
+	if got := Binary.String(); got != "binary" {
+		t.Errorf("Binary.String() = %q, want %q", got, "binary")
+	}
+	if got := Source.String(); got != "source" {
+		t.Errorf("Source.String() = %q, want %q", got, "source")
+	}
+}
+
+func TestParser_parseSourcePackageLine(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		input          string
+		expectedOK     bool
+		expectedBinary string
+		expectedSrc    sourcePackage
+	}{
+		{
+			name:           "dpkg-query binary/source pair",
+			input:          "libssl3 openssl",
+			expectedOK:     true,
+			expectedBinary: "libssl3",
+			expectedSrc:    sourcePackage{name: "openssl"},
+		},
+		{
+			name:           "rpm NEVRA sourcerpm",
+			input:          "openssl-libs openssl-1.1.1k-9.el8.src.rpm",
+			expectedOK:     true,
+			expectedBinary: "openssl-libs",
+			expectedSrc:    sourcePackage{name: "openssl", version: "1.1.1k-9.el8"},
+		},
+		{
+			name:           "rpm -qa --qf NAME VERSION-RELEASE SOURCERPM",
+			input:          "openssl-libs 1.1.1k-9.el8 openssl-1.1.1k-9.el8.src.rpm",
+			expectedOK:     true,
+			expectedBinary: "openssl-libs",
+			expectedSrc:    sourcePackage{name: "openssl", version: "1.1.1k-9.el8"},
+		},
+		{
+			name:           "dpkg-query binary/source/source-version triple",
+			input:          "libssl3 openssl 3.0.2-0ubuntu1",
+			expectedOK:     true,
+			expectedBinary: "libssl3",
+			expectedSrc:    sourcePackage{name: "openssl", version: "3.0.2-0ubuntu1"},
+		},
+		{
+			name:       "too few fields",
+			input:      "libssl3",
+			expectedOK: false,
+		},
+		{
+			name:       "empty line",
+			input:      "",
+			expectedOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary, src, ok := parser.parseSourcePackageLine(tt.input)
+			if ok != tt.expectedOK {
+				t.Fatalf("parseSourcePackageLine(%q) ok = %v, want %v", tt.input, ok, tt.expectedOK)
+			}
+			if !ok {
+				return
+			}
+			if binary != tt.expectedBinary || src != tt.expectedSrc {
+				t.Errorf("parseSourcePackageLine(%q) = (%q, %+v), want (%q, %+v)",
+					tt.input, binary, src, tt.expectedBinary, tt.expectedSrc)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_SourcePackages(t *testing.T) {
+	parser, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+
+	content := `=== Installed Packages Begin
+adduser/noble,now 3.137ubuntu1 all [installed,automatic]
+=== Installed Packages End
+=== Source Packages Begin
+adduser adduser-source
+=== Source Packages End`
+
+	result, err := parser.Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+	if len(result.Packages) != 1 {
+		t.Fatalf("len(result.Packages) = %d, want 1", len(result.Packages))
+	}
+
+	pkg := result.Packages[0]
+	if pkg.SourceName != "adduser-source" {
+		t.Errorf("SourceName = %q, want %q", pkg.SourceName, "adduser-source")
+	}
+	if pkg.Parent == nil || pkg.Parent.Name != "adduser-source" {
+		t.Errorf("Parent = %+v, want source package named %q", pkg.Parent, "adduser-source")
+	}
+	if pkg.Type != Binary {
+		t.Errorf("pkg.Type = %v, want Binary", pkg.Type)
+	}
+	if pkg.Parent != nil && pkg.Parent.Type != Source {
+		t.Errorf("pkg.Parent.Type = %v, want Source", pkg.Parent.Type)
+	}
+}
@@ -0,0 +1,59 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// cycloneDXDocument mirrors the subset of the CycloneDX 1.5 JSON schema this
+// package populates: https://cyclonedx.org/docs/1.5/json/
+type cycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber,omitempty"`
+	Version      int                  `json:"version"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef   string             `json:"bom-ref"`
+	Type     string             `json:"type"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+	Supplier *cycloneDXSupplier `json:"supplier,omitempty"`
+}
+
+type cycloneDXSupplier struct {
+	Name string `json:"name"`
+}
+
+type cycloneDXWriter struct{}
+
+// Write serializes doc as a CycloneDX 1.5 JSON document.
+func (cycloneDXWriter) Write(w io.Writer, doc Document) error {
+	out := cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: doc.SerialNumber,
+		Version:      1,
+		Components:   make([]cycloneDXComponent, 0, len(doc.Components)),
+	}
+	for _, c := range doc.Components {
+		component := cycloneDXComponent{
+			BOMRef:  c.BOMRef,
+			Type:    string(c.Type),
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+		if c.Supplier != "" {
+			component.Supplier = &cycloneDXSupplier{Name: c.Supplier}
+		}
+		out.Components = append(out.Components, component)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
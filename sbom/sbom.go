@@ -0,0 +1,94 @@
+// Package sbom converts parsed VHD inventory data into software bill of
+// materials documents. It is intentionally decoupled from the main package's
+// types: callers build a Document from whatever they parsed and hand it to a
+// Writer, so the encoding (CycloneDX, SPDX, ...) stays independent of how the
+// inventory was collected.
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Format identifies an SBOM encoding this package can produce.
+type Format int
+
+const (
+	// FormatCycloneDX produces a CycloneDX 1.5 JSON document.
+	FormatCycloneDX Format = iota
+	// FormatSPDX produces an SPDX 2.3 JSON document.
+	FormatSPDX
+)
+
+// ComponentType identifies the kind of component a Component describes, using
+// CycloneDX's vocabulary since it is the broader of the two schemas.
+type ComponentType string
+
+const (
+	// ComponentTypeOS is the top-level operating system component.
+	ComponentTypeOS ComponentType = "operating-system"
+	// ComponentTypeLibrary is an installed OS package.
+	ComponentTypeLibrary ComponentType = "library"
+	// ComponentTypeContainer is a pre-pulled container image.
+	ComponentTypeContainer ComponentType = "container"
+)
+
+// Component is a single entry in a Document: an OS package, container image,
+// or the operating system itself. Supplier was added after this package's
+// initial CycloneDX/SPDX support to carry packager/vendor provenance through
+// to both writers.
+type Component struct {
+	BOMRef   string        `json:"bom-ref"`
+	Type     ComponentType `json:"type"`
+	Name     string        `json:"name"`
+	Version  string        `json:"version,omitempty"`
+	PURL     string        `json:"purl,omitempty"`
+	Supplier string        `json:"supplier,omitempty"`
+}
+
+// Document is a format-agnostic SBOM: a serial number for the scan plus the
+// flat list of components discovered in it. A Writer renders it into a
+// specific schema.
+type Document struct {
+	SerialNumber string
+	Components   []Component
+}
+
+// SerialNumber derives a reproducible document serial number from doc's
+// components, so re-scanning unchanged input produces a byte-identical SBOM
+// instead of a new random UUID every run. Callers assign the result to
+// Document.SerialNumber after building the component list.
+func SerialNumber(doc Document) string {
+	h := sha256.New()
+	for _, c := range doc.Components {
+		fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s\n", c.BOMRef, c.Type, c.Name, c.Version, c.PURL, c.Supplier)
+	}
+	sum := h.Sum(nil)
+	return "urn:uuid:" + formatUUID(sum[:16])
+}
+
+// formatUUID renders the first 16 bytes of b in the canonical
+// 8-4-4-4-12 hex-group UUID layout.
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[0:8], s[8:12], s[12:16], s[16:20], s[20:32])
+}
+
+// Writer renders a Document into a specific SBOM schema.
+type Writer interface {
+	Write(w io.Writer, doc Document) error
+}
+
+// NewWriter returns the Writer for the given format.
+func NewWriter(format Format) (Writer, error) {
+	switch format {
+	case FormatCycloneDX:
+		return cycloneDXWriter{}, nil
+	case FormatSPDX:
+		return spdxWriter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format: %d", format)
+	}
+}
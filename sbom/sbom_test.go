@@ -0,0 +1,114 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testDocument() Document {
+	return Document{
+		SerialNumber: "test-serial",
+		Components: []Component{
+			{BOMRef: "os", Type: ComponentTypeOS, Name: "Ubuntu", Version: "24.04", Supplier: "Canonical Ltd."},
+			{BOMRef: "pkg-0", Type: ComponentTypeLibrary, Name: "openssl", Version: "3.0.2", PURL: "pkg:deb/ubuntu/openssl@3.0.2?distro=ubuntu-24.04"},
+		},
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		wantErr bool
+	}{
+		{"CycloneDX", FormatCycloneDX, false},
+		{"SPDX", FormatSPDX, false},
+		{"Unknown", Format(99), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWriter(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewWriter(%v) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCycloneDXWriter_Write(t *testing.T) {
+	w, err := NewWriter(FormatCycloneDX)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf, testDocument()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if out["bomFormat"] != "CycloneDX" {
+		t.Errorf("bomFormat = %v, want CycloneDX", out["bomFormat"])
+	}
+	if out["specVersion"] != "1.5" {
+		t.Errorf("specVersion = %v, want 1.5", out["specVersion"])
+	}
+	if !strings.Contains(buf.String(), "pkg:deb/ubuntu/openssl@3.0.2") {
+		t.Errorf("output missing expected PURL: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"name": "Canonical Ltd."`) {
+		t.Errorf("output missing expected supplier name: %s", buf.String())
+	}
+}
+
+func TestSPDXWriter_Write(t *testing.T) {
+	w, err := NewWriter(FormatSPDX)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf, testDocument()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if out["spdxVersion"] != "SPDX-2.3" {
+		t.Errorf("spdxVersion = %v, want SPDX-2.3", out["spdxVersion"])
+	}
+	packages, ok := out["packages"].([]any)
+	if !ok || len(packages) != 2 {
+		t.Fatalf("packages = %v, want 2 entries", out["packages"])
+	}
+	osPackage, ok := packages[0].(map[string]any)
+	if !ok || osPackage["supplier"] != "Organization: Canonical Ltd." {
+		t.Errorf("os package supplier = %v, want %q", osPackage["supplier"], "Organization: Canonical Ltd.")
+	}
+}
+
+func TestSerialNumber(t *testing.T) {
+	doc := testDocument()
+
+	got := SerialNumber(doc)
+	if !strings.HasPrefix(got, "urn:uuid:") {
+		t.Errorf("SerialNumber() = %q, want urn:uuid: prefix", got)
+	}
+	if got2 := SerialNumber(doc); got2 != got {
+		t.Errorf("SerialNumber() is not reproducible: %q != %q", got2, got)
+	}
+
+	other := testDocument()
+	other.Components[0].Version = "22.04"
+	if got3 := SerialNumber(other); got3 == got {
+		t.Errorf("SerialNumber() did not change for different input: %q", got3)
+	}
+}
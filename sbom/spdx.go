@@ -0,0 +1,67 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// spdxDocument mirrors the subset of the SPDX 2.3 JSON schema this package
+// populates: https://spdx.github.io/spdx-spec/v2.3/
+type spdxDocument struct {
+	SPDXVersion       string        `json:"spdxVersion"`
+	DataLicense       string        `json:"dataLicense"`
+	SPDXID            string        `json:"SPDXID"`
+	Name              string        `json:"name"`
+	DocumentNamespace string        `json:"documentNamespace"`
+	Packages          []spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	SPDXID       string            `json:"SPDXID"`
+	Name         string            `json:"name"`
+	VersionInfo  string            `json:"versionInfo,omitempty"`
+	Supplier     string            `json:"supplier,omitempty"`
+	ExternalRefs []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxWriter struct{}
+
+// Write serializes doc as an SPDX 2.3 JSON document.
+func (spdxWriter) Write(w io.Writer, doc Document) error {
+	out := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "oval-package-parser-scan",
+		DocumentNamespace: "https://github.com/bahe-msft/oval-package-parser/sbom/" + doc.SerialNumber,
+		Packages:          make([]spdxPackage, 0, len(doc.Components)),
+	}
+	for _, c := range doc.Components {
+		pkg := spdxPackage{
+			SPDXID:      "SPDXRef-" + c.BOMRef,
+			Name:        c.Name,
+			VersionInfo: c.Version,
+		}
+		if c.Supplier != "" {
+			pkg.Supplier = "Organization: " + c.Supplier
+		}
+		if c.PURL != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  c.PURL,
+			}}
+		}
+		out.Packages = append(out.Packages, pkg)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bahe-msft/oval-package-parser/sbom"
+)
+
+// buildSBOMDocument converts a ParseResult into a format-agnostic sbom.Document,
+// attaching a Package URL to every package and container image so downstream
+// tools (Grype, Trivy scan-sbom, ...) can route each component to the right
+// vulnerability namespace without re-parsing the VHD log.
+func buildSBOMDocument(result *ParseResult) sbom.Document {
+	doc := sbom.Document{
+		Components: make([]sbom.Component, 0, len(result.Packages)+len(result.ContainerImages)+1),
+	}
+
+	if result.OSRelease != nil {
+		doc.Components = append(doc.Components, sbom.Component{
+			BOMRef:   "os",
+			Type:     sbom.ComponentTypeOS,
+			Name:     result.OSRelease.Name,
+			Version:  result.OSRelease.VersionID,
+			Supplier: distroSupplier(result.OSRelease.Distro),
+		})
+	}
+
+	for i, pkg := range result.Packages {
+		doc.Components = append(doc.Components, sbom.Component{
+			BOMRef:  fmt.Sprintf("pkg-%d", i),
+			Type:    sbom.ComponentTypeLibrary,
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			PURL:    packagePURL(pkg, result.OSRelease),
+		})
+	}
+
+	for i, img := range result.ContainerImages {
+		version := img.Digest
+		if version == "" {
+			version = img.Tag
+		}
+		doc.Components = append(doc.Components, sbom.Component{
+			BOMRef:  fmt.Sprintf("image-%d", i),
+			Type:    sbom.ComponentTypeContainer,
+			Name:    img.Registry + "/" + img.Repository,
+			Version: version,
+			PURL:    imagePURL(img),
+		})
+	}
+
+	doc.SerialNumber = sbom.SerialNumber(doc)
+
+	return doc
+}
+
+// distroSupplier returns the vendor/organization that publishes distro, for
+// the SBOM's operating-system component. Distros this parser does not map to
+// a known vendor report no supplier rather than guessing.
+func distroSupplier(distro OSDistro) string {
+	switch distro {
+	case DistroUbuntu:
+		return "Canonical Ltd."
+	case DistroAzureLinux, DistroMariner:
+		return "Microsoft Corporation"
+	case DistroSUSE:
+		return "SUSE LLC"
+	case DistroDebian:
+		return "Software in the Public Interest, Inc."
+	case DistroRHEL:
+		return "Red Hat, Inc."
+	case DistroCentOS:
+		return "The CentOS Project"
+	case DistroFedora:
+		return "Fedora Project"
+	case DistroRocky:
+		return "Rocky Enterprise Software Foundation"
+	case DistroAlma:
+		return "AlmaLinux OS Foundation"
+	case DistroAmazonLinux:
+		return "Amazon Web Services, Inc."
+	case DistroOracleLinux:
+		return "Oracle Corporation"
+	case DistroAlpine:
+		return "Alpine Linux Development Team"
+	case DistroArch:
+		return "Arch Linux"
+	case DistroPhoton:
+		return "VMware, Inc."
+	default:
+		return ""
+	}
+}
+
+// packagePURL builds the Package URL for pkg, following the same
+// distro-qualifier convention Grype/Syft use to route a package to the
+// correct vulnerability namespace.
+func packagePURL(pkg OSPackage, osRelease *OSRelease) string {
+	if osRelease == nil {
+		return ""
+	}
+
+	name := url.PathEscape(pkg.Name)
+	version := url.PathEscape(pkg.Version)
+
+	switch osRelease.Distro {
+	case DistroUbuntu:
+		return fmt.Sprintf("pkg:deb/ubuntu/%s@%s?distro=ubuntu-%s", name, version, osRelease.VersionID)
+	case DistroAzureLinux:
+		return fmt.Sprintf("pkg:rpm/azurelinux/%s@%s?distro=azurelinux-%s", name, version, osRelease.VersionID)
+	case DistroMariner:
+		return fmt.Sprintf("pkg:rpm/mariner/%s@%s?distro=mariner-%s", name, version, osRelease.VersionID)
+	case DistroSUSE:
+		return fmt.Sprintf("pkg:rpm/%s/%s@%s?distro=%s-%s", osRelease.ID, name, version, osRelease.ID, osRelease.VersionID)
+	default:
+		return ""
+	}
+}
+
+// imagePURL builds a pkg:oci/ Package URL for a pre-pulled container image.
+func imagePURL(img ContainerImage) string {
+	repositoryURL := img.Registry + "/" + img.Repository
+
+	name := img.Repository
+	if slash := strings.LastIndex(name, "/"); slash != -1 {
+		name = name[slash+1:]
+	}
+
+	purl := fmt.Sprintf("pkg:oci/%s?repository_url=%s", url.PathEscape(name), url.QueryEscape(repositoryURL))
+	if img.Digest != "" {
+		purl += "&digest=" + url.QueryEscape(img.Digest)
+	} else if img.Tag != "" {
+		purl += "&tag=" + url.QueryEscape(img.Tag)
+	}
+	return purl
+}
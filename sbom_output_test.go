@@ -0,0 +1,118 @@
+package main
+
+import "testing"
+
+func TestPackagePURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		pkg       OSPackage
+		osRelease *OSRelease
+		expected  string
+	}{
+		{
+			name:      "Ubuntu package",
+			pkg:       OSPackage{Name: "openssl", Version: "3.0.2-0ubuntu1.10"},
+			osRelease: &OSRelease{ID: "ubuntu", VersionID: "24.04", Distro: DistroUbuntu},
+			expected:  "pkg:deb/ubuntu/openssl@3.0.2-0ubuntu1.10?distro=ubuntu-24.04",
+		},
+		{
+			name:      "Azure Linux package",
+			pkg:       OSPackage{Name: "glibc", Version: "2.38-10.azl3"},
+			osRelease: &OSRelease{ID: "azurelinux", VersionID: "3.0", Distro: DistroAzureLinux},
+			expected:  "pkg:rpm/azurelinux/glibc@2.38-10.azl3?distro=azurelinux-3.0",
+		},
+		{
+			name:      "SUSE package",
+			pkg:       OSPackage{Name: "glibc", Version: "2.38-10.150500"},
+			osRelease: &OSRelease{ID: "sles", VersionID: "15.5", Distro: DistroSUSE},
+			expected:  "pkg:rpm/sles/glibc@2.38-10.150500?distro=sles-15.5",
+		},
+		{
+			name:      "No OS release",
+			pkg:       OSPackage{Name: "openssl", Version: "3.0.2"},
+			osRelease: nil,
+			expected:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := packagePURL(tt.pkg, tt.osRelease); got != tt.expected {
+				t.Errorf("packagePURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImagePURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    ContainerImage
+		expected string
+	}{
+		{
+			name:     "Image with tag",
+			image:    ContainerImage{Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause", Tag: "3.6"},
+			expected: "pkg:oci/pause?repository_url=mcr.microsoft.com%2Foss%2Fkubernetes%2Fpause&tag=3.6",
+		},
+		{
+			name:     "Image without tag",
+			image:    ContainerImage{Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause"},
+			expected: "pkg:oci/pause?repository_url=mcr.microsoft.com%2Foss%2Fkubernetes%2Fpause",
+		},
+		{
+			name:     "Image with digest",
+			image:    ContainerImage{Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause", Digest: "sha256:deadbeef"},
+			expected: "pkg:oci/pause?repository_url=mcr.microsoft.com%2Foss%2Fkubernetes%2Fpause&digest=sha256%3Adeadbeef",
+		},
+		{
+			name:     "Image with tag and digest prefers digest",
+			image:    ContainerImage{Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause", Tag: "3.6", Digest: "sha256:deadbeef"},
+			expected: "pkg:oci/pause?repository_url=mcr.microsoft.com%2Foss%2Fkubernetes%2Fpause&digest=sha256%3Adeadbeef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := imagePURL(tt.image); got != tt.expected {
+				t.Errorf("imagePURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildSBOMDocument(t *testing.T) {
+	result := &ParseResult{
+		Packages:        []OSPackage{{Name: "openssl", Version: "3.0.2"}},
+		ContainerImages: []ContainerImage{{Raw: "mcr.microsoft.com/oss/kubernetes/pause:3.6", Registry: "mcr.microsoft.com", Repository: "oss/kubernetes/pause", Tag: "3.6"}},
+		OSRelease:       &OSRelease{ID: "ubuntu", Name: "Ubuntu", VersionID: "24.04", Distro: DistroUbuntu},
+	}
+
+	doc := buildSBOMDocument(result)
+	if len(doc.Components) != 3 {
+		t.Fatalf("len(doc.Components) = %d, want 3", len(doc.Components))
+	}
+	if doc.Components[0].Name != "Ubuntu" {
+		t.Errorf("first component = %q, want OS release component", doc.Components[0].Name)
+	}
+	if doc.Components[0].Supplier != "Canonical Ltd." {
+		t.Errorf("OS component supplier = %q, want %q", doc.Components[0].Supplier, "Canonical Ltd.")
+	}
+	if doc.SerialNumber == "" {
+		t.Error("SerialNumber is empty, want a derived value")
+	}
+
+	doc2 := buildSBOMDocument(result)
+	if doc2.SerialNumber != doc.SerialNumber {
+		t.Errorf("SerialNumber is not reproducible: %q != %q", doc2.SerialNumber, doc.SerialNumber)
+	}
+}
+
+func TestDistroSupplier(t *testing.T) {
+	if got := distroSupplier(DistroUnknown); got != "" {
+		t.Errorf("distroSupplier(DistroUnknown) = %q, want empty", got)
+	}
+	if got := distroSupplier(DistroUbuntu); got != "Canonical Ltd." {
+		t.Errorf("distroSupplier(DistroUbuntu) = %q, want %q", got, "Canonical Ltd.")
+	}
+}
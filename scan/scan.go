@@ -0,0 +1,73 @@
+// Package scan runs vulnerability lookups for a set of packages concurrently,
+// bounding the number of in-flight lookups so a large VHD inventory doesn't
+// open thousands of simultaneous bbolt handles at once.
+package scan
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// Package is the minimal package identity a CheckFunc needs to look up
+// vulnerabilities: the installed name/version plus the source package name to
+// fall back to when the binary name has no advisories of its own.
+type Package struct {
+	Name       string
+	Version    string
+	SourceName string
+}
+
+// CheckFunc looks up vulnerabilities for a single package. Callers provide one
+// that closes over the OS release and Trivy DB handle being scanned against.
+type CheckFunc func(ctx context.Context, pkg Package) ([]types.Advisory, error)
+
+// PackageFinding pairs a package with the vulnerabilities found for it.
+type PackageFinding struct {
+	Package    Package
+	Advisories []types.Advisory
+}
+
+// Scanner runs a CheckFunc across many packages using a bounded worker pool.
+type Scanner struct {
+	check       CheckFunc
+	concurrency int
+}
+
+// NewScanner creates a Scanner that runs check with up to concurrency lookups
+// in flight at once. A concurrency of 0 or less defaults to runtime.NumCPU().
+func NewScanner(check CheckFunc, concurrency int) *Scanner {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	return &Scanner{check: check, concurrency: concurrency}
+}
+
+// Scan looks up vulnerabilities for every package, returning one finding per
+// package in the same order packages was given, regardless of which worker
+// finished first. It stops and returns the first error encountered, cancelling
+// the outstanding lookups via ctx.
+func (s *Scanner) Scan(ctx context.Context, packages []Package) ([]PackageFinding, error) {
+	findings := make([]PackageFinding, len(packages))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.concurrency)
+
+	for i, pkg := range packages {
+		g.Go(func() error {
+			advisories, err := s.check(gctx, pkg)
+			if err != nil {
+				return err
+			}
+			findings[i] = PackageFinding{Package: pkg, Advisories: advisories}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,86 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+func TestScanner_Scan_PreservesOrder(t *testing.T) {
+	packages := make([]Package, 50)
+	for i := range packages {
+		packages[i] = Package{Name: fmt.Sprintf("pkg-%d", i), Version: "1.0"}
+	}
+
+	check := func(_ context.Context, pkg Package) ([]types.Advisory, error) {
+		return []types.Advisory{{VulnerabilityID: "CVE-" + pkg.Name}}, nil
+	}
+
+	findings, err := NewScanner(check, 4).Scan(context.Background(), packages)
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(findings) != len(packages) {
+		t.Fatalf("len(findings) = %d, want %d", len(findings), len(packages))
+	}
+	for i, finding := range findings {
+		if finding.Package.Name != packages[i].Name {
+			t.Errorf("findings[%d].Package.Name = %q, want %q", i, finding.Package.Name, packages[i].Name)
+		}
+		if want := "CVE-" + packages[i].Name; finding.Advisories[0].VulnerabilityID != want {
+			t.Errorf("findings[%d].Advisories[0].VulnerabilityID = %q, want %q", i, finding.Advisories[0].VulnerabilityID, want)
+		}
+	}
+}
+
+func TestScanner_Scan_BoundsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	packages := make([]Package, 20)
+
+	check := func(_ context.Context, _ Package) ([]types.Advisory, error) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+				break
+			}
+		}
+		return nil, nil
+	}
+
+	if _, err := NewScanner(check, 3).Scan(context.Background(), packages); err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("max concurrent lookups = %d, want <= 3", maxInFlight)
+	}
+}
+
+func TestScanner_Scan_PropagatesError(t *testing.T) {
+	packages := []Package{{Name: "a"}, {Name: "b"}}
+	wantErr := errors.New("lookup failed")
+
+	check := func(_ context.Context, pkg Package) ([]types.Advisory, error) {
+		if pkg.Name == "b" {
+			return nil, wantErr
+		}
+		return nil, nil
+	}
+
+	_, err := NewScanner(check, 2).Scan(context.Background(), packages)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Scan() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestNewScanner_DefaultConcurrency(t *testing.T) {
+	s := NewScanner(func(context.Context, Package) ([]types.Advisory, error) { return nil, nil }, 0)
+	if s.concurrency <= 0 {
+		t.Errorf("concurrency = %d, want > 0", s.concurrency)
+	}
+}
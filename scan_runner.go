@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+
+	"github.com/bahe-msft/oval-package-parser/scan"
+)
+
+// vulnFinding pairs an installed package with the active (unfixed)
+// vulnerabilities found for it.
+type vulnFinding struct {
+	pkg   OSPackage
+	vulns []types.Advisory
+}
+
+// scanPackages checks every package in packages for vulnerabilities against
+// osRelease using a bounded worker pool, so large inventories don't scan
+// serially against the Trivy DB one bbolt lookup at a time. The returned
+// slice preserves the order of packages regardless of which worker finished
+// first. A package whose lookup errors is reported with no vulnerabilities
+// rather than aborting the whole scan, matching the previous serial loop's
+// behavior.
+func scanPackages(ctx context.Context, packages []OSPackage, osRelease *OSRelease, concurrency int, filter VulnFilter, source VulnSource) ([]vulnFinding, error) {
+	scanPkgs := make([]scan.Package, len(packages))
+	for i, pkg := range packages {
+		scanPkgs[i] = scan.Package{Name: pkg.Name, Version: pkg.Version, SourceName: pkg.SourceName}
+	}
+
+	checkFn := func(_ context.Context, p scan.Package) ([]types.Advisory, error) {
+		pkg := OSPackage{Name: p.Name, Version: p.Version, SourceName: p.SourceName}
+		vulns, err := checkPackageVulnerabilities(pkg, osRelease, filter, source)
+		if err != nil {
+			fmt.Printf("Package: %s %s - Error: %v\n", pkg.Name, pkg.Version, err)
+			return nil, nil
+		}
+		return vulns, nil
+	}
+
+	results, err := scan.NewScanner(checkFn, concurrency).Scan(ctx, scanPkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]vulnFinding, len(results))
+	for i, result := range results {
+		findings[i] = vulnFinding{pkg: packages[i], vulns: result.Advisories}
+	}
+	return findings, nil
+}
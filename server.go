@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+// scanURLRequest is the JSON body POST /scan accepts as an alternative to a
+// raw VHD log: `{"url": "..."}`, fetched (and cached) the same way the CLI
+// fetches its positional source argument.
+type scanURLRequest struct {
+	URL string `json:"url"`
+}
+
+// scanFinding is the JSON-serializable form of a vulnFinding: an installed
+// package paired with the active vulnerabilities found for it.
+type scanFinding struct {
+	Package         OSPackage        `json:"package"`
+	Vulnerabilities []types.Advisory `json:"vulnerabilities,omitempty"`
+}
+
+// scanResponse is the POST /scan response body: the parsed inventory plus
+// the vulnerability findings for it.
+type scanResponse struct {
+	*ParseResult
+	Findings []scanFinding `json:"findings,omitempty"`
+}
+
+// Server runs the `serve` subcommand's HTTP API: POST /scan, GET /healthz,
+// and GET /metrics, sharing one warmed Parser, ResultCache, and Metrics
+// registry across every request.
+type Server struct {
+	parser      *Parser
+	cache       *ResultCache
+	metrics     *Metrics
+	concurrency int
+}
+
+// NewServer creates a Server that parses with parser, caches fetched sources
+// in cache, and scans for vulnerabilities with up to concurrency lookups in
+// flight per request.
+func NewServer(parser *Parser, cache *ResultCache, concurrency int) *Server {
+	return &Server{
+		parser:      parser,
+		cache:       cache,
+		metrics:     NewMetrics(),
+		concurrency: concurrency,
+	}
+}
+
+// Handler returns the http.Handler serving the API's routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scan", s.handleScan)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := s.metrics.Render(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	start := time.Now()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var result *ParseResult
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var req scanURLRequest
+		if err := json.Unmarshal(body, &req); err != nil || req.URL == "" {
+			http.Error(w, `expected {"url": "..."} for application/json requests`, http.StatusBadRequest)
+			return
+		}
+		result, err = s.cache.FetchAndParse(s.parser, req.URL)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to fetch and parse %s: %v", req.URL, err), http.StatusBadGateway)
+			return
+		}
+	} else {
+		result, err = s.parser.Parse(bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse request body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := scanResponse{ParseResult: result}
+	distro := "unknown"
+	if result.OSRelease != nil {
+		distro = result.OSRelease.Distro.String()
+
+		findings, err := scanPackages(r.Context(), result.Packages, result.OSRelease, s.concurrency, VulnFilter{}, nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("vulnerability scan failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		fixable, unfixable := 0, 0
+		resp.Findings = make([]scanFinding, len(findings))
+		for i, finding := range findings {
+			resp.Findings[i] = scanFinding{Package: finding.pkg, Vulnerabilities: finding.vulns}
+			for _, vuln := range finding.vulns {
+				if vuln.FixedVersion != "" {
+					fixable++
+				} else {
+					unfixable++
+				}
+			}
+		}
+		s.metrics.ObserveVulnsFound(distro, true, fixable)
+		s.metrics.ObserveVulnsFound(distro, false, unfixable)
+	}
+	s.metrics.ObservePackagesScanned(distro, len(result.Packages))
+	s.metrics.ObserveScanDuration(time.Since(start))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("failed to encode /scan response: %v", err)
+	}
+}
+
+// Serve runs the HTTP API on addr until ctx is cancelled (e.g. by a SIGTERM),
+// then shuts the server down gracefully.
+func Serve(ctx context.Context, addr string, srv *Server) error {
+	httpServer := &http.Server{Addr: addr, Handler: srv.Handler()}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+			return
+		}
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		log.Printf("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	p, err := NewParser()
+	if err != nil {
+		t.Fatalf("NewParser() failed: %v", err)
+	}
+	cache, err := NewResultCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewResultCache() failed: %v", err)
+	}
+	return NewServer(p, cache, 1)
+}
+
+func TestServer_Healthz(t *testing.T) {
+	srv := newTestServer(t)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+}
+
+func TestServer_ScanRawBody(t *testing.T) {
+	srv := newTestServer(t)
+	content := "=== Installed Packages Begin\nadduser/noble,now 3.137ubuntu1 all [installed,automatic]\n=== Installed Packages End"
+
+	req := httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(content))
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp scanResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Packages) != 1 || resp.Packages[0].Name != "adduser" {
+		t.Errorf("resp.Packages = %v, want [adduser]", resp.Packages)
+	}
+	// No OS release info in this fixture, so vulnerability scanning is
+	// skipped and no findings should be present.
+	if resp.Findings != nil {
+		t.Errorf("resp.Findings = %v, want nil", resp.Findings)
+	}
+}
+
+func TestServer_ScanURL_BadRequest(t *testing.T) {
+	srv := newTestServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(`{"url": ""}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestServer_Scan_MethodNotAllowed(t *testing.T) {
+	srv := newTestServer(t)
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/scan", nil))
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	srv := newTestServer(t)
+	content := "=== Installed Packages Begin\nadduser/noble,now 3.137ubuntu1 all [installed,automatic]\n=== Installed Packages End"
+
+	req := httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(content))
+	srv.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	rr := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, `ovalpp_packages_scanned_total{distro="unknown"} 1`) {
+		t.Errorf("metrics output missing expected packages_scanned line, got:\n%s", body)
+	}
+	if !strings.Contains(body, "ovalpp_scan_duration_seconds_count 1") {
+		t.Errorf("metrics output missing expected scan_duration count, got:\n%s", body)
+	}
+}
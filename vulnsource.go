@@ -0,0 +1,387 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+// VulnSource resolves the advisories published against a package name, the
+// way vulnAdvisoriesByName does for Trivy DB. checkPackageVulnerabilities is
+// written against this interface rather than calling Trivy DB directly so a
+// second backend can be swapped in via --source without touching the
+// filtering/consolidation logic around it.
+type VulnSource interface {
+	Advisories(pkgName string, osRelease *OSRelease) ([]types.Advisory, error)
+}
+
+// trivyDBSource is the default VulnSource: the bbolt-backed Trivy DB this
+// package has always queried.
+type trivyDBSource struct{}
+
+func (trivyDBSource) Advisories(pkgName string, osRelease *OSRelease) ([]types.Advisory, error) {
+	return vulnAdvisoriesByName(pkgName, osRelease)
+}
+
+// resolveVulnSource turns the --source flag value into the VulnSource
+// checkPackageVulnerabilities should query. "trivy" (the default, and what ""
+// and the legacy "auto" both fall back to) is the bbolt-backed Trivy DB this
+// package has always queried. "ubuntu-cve" is strictly opt-in: the Ubuntu CVE
+// Tracker has no by-package index, so querying it means walking its entire
+// active+retired corpus over HTTP, which is fine for a deliberate one-off
+// lookup but far too expensive to run automatically on every Ubuntu scan -
+// which is why an earlier "auto" blended it in by default and that default
+// was removed; picking "ubuntu-cve" against a non-Ubuntu distro still
+// resolves here, it just errors on the first Advisories call. distro is
+// accepted so a later source needing distro-specific setup doesn't require a
+// signature change here.
+func resolveVulnSource(value string, distro OSDistro) (VulnSource, error) {
+	switch value {
+	case "", "auto", "trivy":
+		return trivyDBSource{}, nil
+	case "ubuntu-cve":
+		return newUbuntuCVETrackerSource(), nil
+	default:
+		return nil, fmt.Errorf("unknown vulnerability source %q (want trivy or ubuntu-cve)", value)
+	}
+}
+
+// defaultUbuntuCVETrackerBaseURL is git.launchpad.net's cgit "plain" view of
+// the ubuntu-cve-tracker repo, which serves a tracked CVE file's raw content
+// (and a directory's file listing) without requiring a full git clone.
+const defaultUbuntuCVETrackerBaseURL = "https://git.launchpad.net/ubuntu-cve-tracker/plain"
+
+// ubuntuCodenames maps an Ubuntu VERSION_ID to the release codename the CVE
+// tracker's per-release status lines key on, e.g. "focal_openssl: released
+// (1.1.1f-1ubuntu2.16)". Only the releases still carried in the tracker's
+// active/retired files are listed here; an unmapped VERSION_ID is reported
+// as an error rather than guessed at.
+var ubuntuCodenames = map[string]string{
+	"14.04": "trusty",
+	"16.04": "xenial",
+	"18.04": "bionic",
+	"20.04": "focal",
+	"22.04": "jammy",
+	"24.04": "noble",
+}
+
+// ubuntuCodename resolves an Ubuntu VERSION_ID (e.g. "20.04") to the release
+// codename (e.g. "focal") the CVE tracker uses in its status lines.
+func ubuntuCodename(versionID string) (string, error) {
+	codename, ok := ubuntuCodenames[versionID]
+	if !ok {
+		return "", fmt.Errorf("no known Ubuntu CVE tracker codename for version %q", versionID)
+	}
+	return codename, nil
+}
+
+var (
+	cveCandidateRegex = regexp.MustCompile(`(?m)^Candidate:\s*(CVE-\d{4}-\d+)`)
+	cvePriorityRegex  = regexp.MustCompile(`(?m)^Priority:\s*(\S+)`)
+	cveIDRegex        = regexp.MustCompile(`CVE-\d{4}-\d+`)
+)
+
+// ubuntuCVETrackerStatus maps a CVE tracker release-status word to the
+// equivalent types.Status. "released" is handled by the caller, which also
+// has the fixed version parenthesized alongside it.
+func ubuntuCVETrackerStatus(status string) types.Status {
+	switch status {
+	case "released":
+		return types.StatusFixed
+	case "DNE", "not-affected":
+		return types.StatusNotAffected
+	case "ignored":
+		return types.StatusWillNotFix
+	case "deferred":
+		return types.StatusFixDeferred
+	default: // needed, needs-triage, active, pending, and anything else still open
+		return types.StatusAffected
+	}
+}
+
+// severityFromPriority maps a CVE file's "Priority:" line to a
+// types.Severity, returning SeverityUnknown if the file has no priority line
+// or uses a word this mapping doesn't recognize (e.g. "untriaged").
+func severityFromPriority(match []string) types.Severity {
+	if match == nil {
+		return types.SeverityUnknown
+	}
+	switch match[1] {
+	case "negligible", "low":
+		return types.SeverityLow
+	case "medium":
+		return types.SeverityMedium
+	case "high":
+		return types.SeverityHigh
+	case "critical":
+		return types.SeverityCritical
+	default:
+		return types.SeverityUnknown
+	}
+}
+
+// parseCVETrackerFile parses one CVE tracker file's content (the format
+// shared by both its active/ and retired/ directories) into the
+// types.Advisory for pkgName on codename, returning ok=false if the file has
+// no status line for that package/release pair at all.
+func parseCVETrackerFile(content, pkgName, codename string) (advisory types.Advisory, ok bool) {
+	advisory, ok = parseCVETrackerFilePackages(content, codename)[pkgName]
+	return advisory, ok
+}
+
+// allPackagesStatusLineRegex matches every per-package status line for
+// codename in a tracker file, e.g. "focal_openssl: released
+// (1.1.1f-1ubuntu2.16)" or "focal_libssl: needed", capturing the package
+// name alongside the status word and optional fixed version.
+func allPackagesStatusLineRegex(codename string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(codename+"_") + `(\S+):\s*(\S+)(?:\s+\(([^)]+)\))?\s*$`)
+}
+
+// parseCVETrackerFilePackages parses every package this tracker file records
+// a codename status for in one pass, keyed by package name. A single file
+// covers exactly one CVE, so every line it finds shares that CVE's ID and
+// severity. This is what lets the index build walk the corpus once instead
+// of once per package: parseCVETrackerFile above is just this function
+// narrowed to a single package name.
+func parseCVETrackerFilePackages(content, codename string) map[string]types.Advisory {
+	candidate := cveCandidateRegex.FindStringSubmatch(content)
+	if candidate == nil {
+		return nil
+	}
+	severity := severityFromPriority(cvePriorityRegex.FindStringSubmatch(content))
+
+	packages := make(map[string]types.Advisory)
+	for _, m := range allPackagesStatusLineRegex(codename).FindAllStringSubmatch(content, -1) {
+		pkgName, statusWord, fixedVersion := m[1], m[2], m[3]
+		status := ubuntuCVETrackerStatus(statusWord)
+		advisory := types.Advisory{
+			VulnerabilityID: candidate[1],
+			Status:          status,
+			Severity:        severity,
+		}
+		if status == types.StatusFixed {
+			advisory.FixedVersion = fixedVersion
+		}
+		packages[pkgName] = advisory
+	}
+	return packages
+}
+
+// ubuntuCVETrackerSource is a VulnSource that fetches advisories directly
+// from the Ubuntu CVE Tracker git repo instead of a prebuilt database, in
+// exchange for being hours ahead of Trivy DB's rebuild cadence and for
+// surfacing needed/pending/ignored/DNE statuses Trivy's advisories don't
+// carry. There is no by-package index to query directly, so the first
+// Advisories call for a given Ubuntu release indexes its entire
+// active+retired corpus by package name once; every later call for that same
+// release, regardless of which package it's for, is then a plain map lookup
+// instead of its own walk of the corpus. A scan still touches the network
+// for exactly one release's worth of CVE files no matter how many packages
+// it checks - not once per package.
+type ubuntuCVETrackerSource struct {
+	baseURL string
+
+	mu        sync.Mutex
+	dirCache  map[string][]string
+	fileCache map[string]string
+
+	indexOnce map[string]*sync.Once
+	index     map[string]map[string][]types.Advisory
+	indexErr  map[string]error
+}
+
+// newUbuntuCVETrackerSource creates an ubuntuCVETrackerSource pointed at the
+// public Ubuntu CVE Tracker.
+func newUbuntuCVETrackerSource() *ubuntuCVETrackerSource {
+	return &ubuntuCVETrackerSource{baseURL: defaultUbuntuCVETrackerBaseURL}
+}
+
+func (s *ubuntuCVETrackerSource) Advisories(pkgName string, osRelease *OSRelease) ([]types.Advisory, error) {
+	if osRelease == nil || osRelease.Distro != DistroUbuntu {
+		return nil, fmt.Errorf("ubuntu CVE tracker source only supports Ubuntu, got %v", osRelease.Distro)
+	}
+	codename, err := ubuntuCodename(osRelease.VersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := s.packageIndex(codename)
+	if err != nil {
+		return nil, err
+	}
+	return index[pkgName], nil
+}
+
+// packageIndex returns codename's pkgName->advisories index, building it
+// from the tracker's active+retired corpus on the first call and reusing
+// that result for every later call regardless of which package is asked
+// for. Concurrent first calls for the same codename block on the same
+// sync.Once rather than each kicking off their own corpus walk. A failed
+// build's Once is discarded rather than kept, so a transient fetch error
+// doesn't permanently poison this codename for the rest of the source's
+// life - the next call simply tries the corpus walk again.
+func (s *ubuntuCVETrackerSource) packageIndex(codename string) (map[string][]types.Advisory, error) {
+	s.mu.Lock()
+	if s.indexOnce == nil {
+		s.indexOnce = make(map[string]*sync.Once)
+	}
+	once, ok := s.indexOnce[codename]
+	if !ok {
+		once = &sync.Once{}
+		s.indexOnce[codename] = once
+	}
+	s.mu.Unlock()
+
+	once.Do(func() {
+		index, err := s.buildIndex(codename)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil {
+			if s.indexErr == nil {
+				s.indexErr = make(map[string]error)
+			}
+			s.indexErr[codename] = err
+			delete(s.indexOnce, codename)
+			return
+		}
+		if s.index == nil {
+			s.index = make(map[string]map[string][]types.Advisory)
+		}
+		s.index[codename] = index
+		delete(s.indexErr, codename)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err, ok := s.indexErr[codename]; ok {
+		delete(s.indexErr, codename)
+		return nil, err
+	}
+	return s.index[codename], nil
+}
+
+// buildIndex walks the tracker's active and retired corpora exactly once,
+// parsing every CVE file's codename status lines for every package it
+// mentions (not just one), so the resulting map answers an Advisories call
+// for any package without fetching anything further.
+func (s *ubuntuCVETrackerSource) buildIndex(codename string) (map[string][]types.Advisory, error) {
+	index := make(map[string][]types.Advisory)
+	for _, dir := range []string{"active", "retired"} {
+		cveIDs, err := s.listCVEIDs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s CVEs: %w", dir, err)
+		}
+		dropped := 0
+		for _, cveID := range cveIDs {
+			content, err := s.fetchCVEFile(dir, cveID)
+			if err != nil {
+				dropped++
+				continue
+			}
+			for name, advisory := range parseCVETrackerFilePackages(content, codename) {
+				index[name] = append(index[name], advisory)
+			}
+		}
+		if dropped > 0 {
+			fmt.Fprintf(os.Stderr, "warning: ubuntu CVE tracker: failed to fetch %d/%d %s CVE files while indexing %s, results may be incomplete\n", dropped, len(cveIDs), dir, codename)
+		}
+	}
+	return index, nil
+}
+
+// listCVEIDs fetches dir's file listing and extracts the CVE IDs it
+// contains, deduplicated and sorted for deterministic iteration order. The
+// listing is cached by dir, since every package scanned looks it up again.
+func (s *ubuntuCVETrackerSource) listCVEIDs(dir string) ([]string, error) {
+	if ids, ok := s.cachedDir(dir); ok {
+		return ids, nil
+	}
+
+	body, err := s.fetch(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, id := range cveIDRegex.FindAllString(body, -1) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	s.cacheDir(dir, ids)
+	return ids, nil
+}
+
+// fetchCVEFile fetches the raw content of a single tracked CVE file,
+// caching it by dir+cveID since every package scanned re-reads the same
+// files to check its own status line.
+func (s *ubuntuCVETrackerSource) fetchCVEFile(dir, cveID string) (string, error) {
+	key := dir + "/" + cveID
+	if content, ok := s.cachedFile(key); ok {
+		return content, nil
+	}
+	content, err := s.fetch(key)
+	if err != nil {
+		return "", err
+	}
+	s.cacheFile(key, content)
+	return content, nil
+}
+
+func (s *ubuntuCVETrackerSource) cachedDir(dir string) ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, ok := s.dirCache[dir]
+	return ids, ok
+}
+
+func (s *ubuntuCVETrackerSource) cacheDir(dir string, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirCache == nil {
+		s.dirCache = make(map[string][]string)
+	}
+	s.dirCache[dir] = ids
+}
+
+func (s *ubuntuCVETrackerSource) cachedFile(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.fileCache[key]
+	return content, ok
+}
+
+func (s *ubuntuCVETrackerSource) cacheFile(key, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fileCache == nil {
+		s.fileCache = make(map[string]string)
+	}
+	s.fileCache[key] = content
+}
+
+// fetch retrieves path under the tracker's base URL, reusing fetchContent so
+// this source follows the same HTTP-or-local-file convention as every other
+// input this package reads.
+func (s *ubuntuCVETrackerSource) fetch(path string) (string, error) {
+	reader, err := fetchContent(s.baseURL + "/" + path)
+	if err != nil {
+		return "", err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
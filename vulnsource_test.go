@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aquasecurity/trivy-db/pkg/types"
+)
+
+// sampleCVEFile is a trimmed excerpt of a real ubuntu-cve-tracker active/
+// file, covering a released fix on focal and a still-open status on jammy.
+const sampleCVEFile = `Candidate: CVE-2023-12345
+PublicDate: 2023-01-01
+References:
+ https://example.com/CVE-2023-12345
+Description:
+ An example description of the vulnerability.
+Ubuntu-Description:
+Notes:
+Priority: high
+Discovered-by:
+Assigned-to:
+
+Patches_openssl:
+
+focal_openssl: released (1.1.1f-1ubuntu2.16)
+jammy_openssl: needed
+`
+
+func TestUbuntuCodename(t *testing.T) {
+	tests := []struct {
+		versionID string
+		want      string
+		wantErr   bool
+	}{
+		{"20.04", "focal", false},
+		{"22.04", "jammy", false},
+		{"24.04", "noble", false},
+		{"99.04", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versionID, func(t *testing.T) {
+			got, err := ubuntuCodename(tt.versionID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ubuntuCodename(%q) expected error, got nil", tt.versionID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ubuntuCodename(%q) unexpected error: %v", tt.versionID, err)
+			}
+			if got != tt.want {
+				t.Errorf("ubuntuCodename(%q) = %q, want %q", tt.versionID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCVETrackerFile(t *testing.T) {
+	advisory, ok := parseCVETrackerFile(sampleCVEFile, "openssl", "focal")
+	if !ok {
+		t.Fatal("expected focal_openssl status line to be found")
+	}
+	if advisory.VulnerabilityID != "CVE-2023-12345" {
+		t.Errorf("VulnerabilityID = %q, want CVE-2023-12345", advisory.VulnerabilityID)
+	}
+	if advisory.Status != types.StatusFixed {
+		t.Errorf("Status = %v, want %v", advisory.Status, types.StatusFixed)
+	}
+	if advisory.FixedVersion != "1.1.1f-1ubuntu2.16" {
+		t.Errorf("FixedVersion = %q, want 1.1.1f-1ubuntu2.16", advisory.FixedVersion)
+	}
+	if advisory.Severity != types.SeverityHigh {
+		t.Errorf("Severity = %v, want %v", advisory.Severity, types.SeverityHigh)
+	}
+
+	advisory, ok = parseCVETrackerFile(sampleCVEFile, "openssl", "jammy")
+	if !ok {
+		t.Fatal("expected jammy_openssl status line to be found")
+	}
+	if advisory.Status != types.StatusAffected {
+		t.Errorf("Status = %v, want %v", advisory.Status, types.StatusAffected)
+	}
+	if advisory.FixedVersion != "" {
+		t.Errorf("FixedVersion = %q, want empty for a still-open status", advisory.FixedVersion)
+	}
+
+	if _, ok := parseCVETrackerFile(sampleCVEFile, "curl", "focal"); ok {
+		t.Error("expected no status line for a package the file doesn't track")
+	}
+}
+
+func TestUbuntuCVETrackerSource_Advisories(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/active", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="CVE-2023-12345">CVE-2023-12345</a>`)
+	})
+	mux.HandleFunc("/active/CVE-2023-12345", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleCVEFile)
+	})
+	mux.HandleFunc("/retired", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source := &ubuntuCVETrackerSource{baseURL: server.URL}
+	osRelease := &OSRelease{Distro: DistroUbuntu, VersionID: "20.04"}
+
+	advisories, err := source.Advisories("openssl", osRelease)
+	if err != nil {
+		t.Fatalf("Advisories() error: %v", err)
+	}
+	if len(advisories) != 1 {
+		t.Fatalf("got %d advisories, want 1: %+v", len(advisories), advisories)
+	}
+	if advisories[0].VulnerabilityID != "CVE-2023-12345" {
+		t.Errorf("VulnerabilityID = %q, want CVE-2023-12345", advisories[0].VulnerabilityID)
+	}
+	if advisories[0].FixedVersion != "1.1.1f-1ubuntu2.16" {
+		t.Errorf("FixedVersion = %q, want 1.1.1f-1ubuntu2.16", advisories[0].FixedVersion)
+	}
+}
+
+// TestUbuntuCVETrackerSource_Advisories_RetriesAfterFailedIndex checks that a
+// failed index build doesn't permanently poison a codename: a transient
+// listing failure on the first call must not stop a later call from trying
+// the corpus walk again.
+func TestUbuntuCVETrackerSource_Advisories_RetriesAfterFailedIndex(t *testing.T) {
+	fail := true
+	mux := http.NewServeMux()
+	mux.HandleFunc("/active", func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `<a href="CVE-2023-12345">CVE-2023-12345</a>`)
+	})
+	mux.HandleFunc("/active/CVE-2023-12345", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleCVEFile)
+	})
+	mux.HandleFunc("/retired", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source := &ubuntuCVETrackerSource{baseURL: server.URL}
+	osRelease := &OSRelease{Distro: DistroUbuntu, VersionID: "20.04"}
+
+	if _, err := source.Advisories("openssl", osRelease); err == nil {
+		t.Fatal("expected the first call to fail while the server is erroring")
+	}
+
+	fail = false
+	advisories, err := source.Advisories("openssl", osRelease)
+	if err != nil {
+		t.Fatalf("Advisories() after recovery error: %v", err)
+	}
+	if len(advisories) != 1 || advisories[0].VulnerabilityID != "CVE-2023-12345" {
+		t.Errorf("advisories = %+v, want the sample file's single advisory", advisories)
+	}
+}
+
+// TestUbuntuCVETrackerSource_Advisories_IndexesCorpusOnce checks that a
+// second package looked up against the same Ubuntu release reuses the first
+// call's index instead of re-walking the corpus - the whole point of
+// indexing by codename instead of fetching per package.
+func TestUbuntuCVETrackerSource_Advisories_IndexesCorpusOnce(t *testing.T) {
+	var activeHits, cveFileHits int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/active", func(w http.ResponseWriter, r *http.Request) {
+		activeHits++
+		fmt.Fprint(w, `<a href="CVE-2023-12345">CVE-2023-12345</a>`)
+	})
+	mux.HandleFunc("/active/CVE-2023-12345", func(w http.ResponseWriter, r *http.Request) {
+		cveFileHits++
+		fmt.Fprint(w, sampleCVEFile)
+	})
+	mux.HandleFunc("/retired", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source := &ubuntuCVETrackerSource{baseURL: server.URL}
+	osRelease := &OSRelease{Distro: DistroUbuntu, VersionID: "20.04"}
+
+	if _, err := source.Advisories("openssl", osRelease); err != nil {
+		t.Fatalf("Advisories(openssl) error: %v", err)
+	}
+	if activeHits != 1 || cveFileHits != 1 {
+		t.Fatalf("after first package: activeHits=%d cveFileHits=%d, want 1, 1", activeHits, cveFileHits)
+	}
+
+	advisories, err := source.Advisories("libssl", osRelease)
+	if err != nil {
+		t.Fatalf("Advisories(libssl) error: %v", err)
+	}
+	if activeHits != 1 || cveFileHits != 1 {
+		t.Errorf("after second package: activeHits=%d cveFileHits=%d, want unchanged 1, 1 (corpus should be indexed once)", activeHits, cveFileHits)
+	}
+	if len(advisories) != 0 {
+		t.Errorf("Advisories(libssl) = %+v, want none: the sample file has no libssl status line", advisories)
+	}
+}
+
+func TestParseCVETrackerFilePackages(t *testing.T) {
+	packages := parseCVETrackerFilePackages(sampleCVEFile, "focal")
+	adv, ok := packages["openssl"]
+	if !ok {
+		t.Fatal("expected focal_openssl in the parsed package index")
+	}
+	if adv.Status != types.StatusFixed || adv.FixedVersion != "1.1.1f-1ubuntu2.16" {
+		t.Errorf("packages[openssl] = %+v, want StatusFixed/1.1.1f-1ubuntu2.16", adv)
+	}
+	if _, ok := packages["libssl"]; ok {
+		t.Error("expected no focal_libssl status line in the sample file")
+	}
+}
+
+func TestUbuntuCVETrackerSource_Advisories_NonUbuntu(t *testing.T) {
+	source := newUbuntuCVETrackerSource()
+	osRelease := &OSRelease{Distro: DistroDebian, VersionID: "12"}
+
+	if _, err := source.Advisories("openssl", osRelease); err == nil {
+		t.Error("expected error for a non-Ubuntu OSRelease, got nil")
+	}
+}
+
+func TestResolveVulnSource(t *testing.T) {
+	tests := []struct {
+		value   string
+		distro  OSDistro
+		wantErr bool
+		want    VulnSource
+	}{
+		{value: "trivy", distro: DistroUbuntu, want: trivyDBSource{}},
+		{value: "ubuntu-cve", distro: DistroUbuntu, want: &ubuntuCVETrackerSource{}},
+		{value: "auto", distro: DistroUbuntu, want: trivyDBSource{}},
+		{value: "", distro: DistroUbuntu, want: trivyDBSource{}},
+		{value: "auto", distro: DistroSUSE, want: trivyDBSource{}},
+		{value: "bogus", distro: DistroUbuntu, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+"/"+tt.distro.String(), func(t *testing.T) {
+			source, err := resolveVulnSource(tt.value, tt.distro)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveVulnSource(%q, %v) expected error, got nil", tt.value, tt.distro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveVulnSource(%q, %v) unexpected error: %v", tt.value, tt.distro, err)
+			}
+			switch tt.want.(type) {
+			case trivyDBSource:
+				if _, ok := source.(trivyDBSource); !ok {
+					t.Errorf("resolveVulnSource(%q, %v) = %T, want trivyDBSource", tt.value, tt.distro, source)
+				}
+			case *ubuntuCVETrackerSource:
+				if _, ok := source.(*ubuntuCVETrackerSource); !ok {
+					t.Errorf("resolveVulnSource(%q, %v) = %T, want *ubuntuCVETrackerSource", tt.value, tt.distro, source)
+				}
+			}
+		})
+	}
+}